@@ -0,0 +1,158 @@
+package coroutine
+
+import (
+	"fmt"
+	"time"
+)
+
+// sending is a value waiting to be received from a full channel.
+type sending[T any] struct {
+	value T
+	taken bool
+}
+
+// Chan passes values between coroutines of the same group. Sending to a
+// full channel, or receiving from an empty one, yields until the other side
+// is ready instead of blocking the goroutine. A channel with a size of zero
+// is a rendezvous: each send waits until the value has been received.
+type Chan[T any] struct {
+	name    string
+	size    int
+	buf     []T
+	senders []*sending[T]
+	closed  bool
+}
+
+// NewChan creates a channel that holds up to size values without a
+// receiver.
+func NewChan[T any](name string, size int) *Chan[T] {
+	return &Chan[T]{name: name, size: size}
+}
+
+// Len returns the number of values held in the channel.
+func (ch *Chan[T]) Len() int {
+	return len(ch.buf)
+}
+
+// Cap returns the number of values that can be held in the channel.
+func (ch *Chan[T]) Cap() int {
+	return ch.size
+}
+
+// Closed returns true if the channel has been closed.
+func (ch *Chan[T]) Closed() bool {
+	return ch.closed
+}
+
+// Close closes the channel. Receivers get the values still held in the
+// channel and are then told the channel is closed. Like a Go channel,
+// sending on a closed channel, or closing a channel twice, panics. This
+// includes coroutines waiting to send when the channel is closed.
+func (ch *Chan[T]) Close() {
+	if ch.closed {
+		panic("coroutine: close of closed channel")
+	}
+	ch.closed = true
+}
+
+// Send places the value in the channel or yields until there is room for
+// it. On a rendezvous channel, Send yields until the value has been
+// received. Returns true if the coroutine was canceled.
+func (ch *Chan[T]) Send(co *C, v T) bool {
+	if ch.closed {
+		panic("coroutine: send on closed channel")
+	}
+	if len(ch.buf) < ch.size && len(ch.senders) == 0 {
+		ch.buf = append(ch.buf, v)
+		return false
+	}
+	s := &sending[T]{value: v}
+	ch.senders = append(ch.senders, s)
+	cond := until(fmt.Sprintf("Send(%v)", ch.name), func() bool {
+		return s.taken || ch.closed
+	})
+	_, done := co.Wait(cond)
+	if s.taken {
+		return done
+	}
+	ch.remove(s)
+	if done {
+		return true
+	}
+	panic("coroutine: send on closed channel")
+}
+
+// Recv takes the next value from the channel or yields until there is one.
+// Returns false for ok if the channel has been closed and is empty and
+// true for done if the coroutine was canceled.
+func (ch *Chan[T]) Recv(co *C) (v T, ok bool, done bool) {
+	return ch.recv(co, nil)
+}
+
+// RecvUntil is like Recv but gives up once the duration has elapsed. On a
+// timeout, both ok and done are false.
+func (ch *Chan[T]) RecvUntil(co *C, d time.Duration) (v T, ok bool, done bool) {
+	deadline := co.group.Now().Add(d)
+	return ch.recv(co, &deadline)
+}
+
+func (ch *Chan[T]) recv(co *C, deadline *time.Time) (v T, ok bool, done bool) {
+	ready := until(fmt.Sprintf("Recv(%v)", ch.name), func() bool {
+		return len(ch.buf) > 0 || len(ch.senders) > 0 || ch.closed
+	})
+	for {
+		if co.cause != nil {
+			return v, false, true
+		}
+		if v, ok := ch.take(); ok {
+			return v, true, false
+		}
+		if ch.closed {
+			return v, false, false
+		}
+		cond := ready
+		if deadline != nil {
+			remaining := deadline.Sub(co.group.Now())
+			if remaining <= 0 {
+				return v, false, false
+			}
+			cond = AnyOf(ready, Timeout(remaining))
+		}
+		if _, done := co.Wait(cond); done {
+			return v, false, true
+		}
+	}
+}
+
+// take removes the next value from the channel, if there is one, and lets
+// the first waiting sender move its value into the channel. Waiting senders
+// are left alone once the channel is closed since they are going to panic.
+func (ch *Chan[T]) take() (v T, ok bool) {
+	if len(ch.buf) > 0 {
+		v = ch.buf[0]
+		ch.buf = ch.buf[1:]
+		if len(ch.senders) > 0 && !ch.closed {
+			s := ch.senders[0]
+			ch.senders = ch.senders[1:]
+			s.taken = true
+			ch.buf = append(ch.buf, s.value)
+		}
+		return v, true
+	}
+	if len(ch.senders) > 0 && !ch.closed {
+		s := ch.senders[0]
+		ch.senders = ch.senders[1:]
+		s.taken = true
+		return s.value, true
+	}
+	return v, false
+}
+
+func (ch *Chan[T]) remove(s *sending[T]) {
+	for i, sender := range ch.senders {
+		if sender == s {
+			ch.senders = append(ch.senders[:i], ch.senders[i+1:]...)
+			return
+		}
+	}
+}
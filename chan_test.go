@@ -0,0 +1,158 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChanRendezvous(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	ch := NewChan[int]("scores", 0)
+	var log []string
+	g.NewCoroutine(func(co *C) {
+		for i := 1; i <= 2; i++ {
+			log = append(log, "send")
+			if done := ch.Send(co, i*10); done {
+				return
+			}
+			log = append(log, "sent")
+		}
+		ch.Close()
+	})
+	var received []int
+	g.NewCoroutine(func(co *C) {
+		for {
+			v, ok, done := ch.Recv(co)
+			if !ok || done {
+				log = append(log, "closed")
+				return
+			}
+			received = append(received, v)
+			log = append(log, "recv")
+			co.WaitFor(testEvent("next"))
+		}
+	})
+	g.Tick()
+	g.Post(testEvent("next"))
+	g.Tick()
+	g.Post(testEvent("next"))
+	g.Tick()
+
+	if want := []int{10, 20}; !reflect.DeepEqual(received, want) {
+		t.Errorf("\n have: %v \n want: %v", received, want)
+	}
+	want := []string{"send", "recv", "sent", "send", "recv", "sent", "closed"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("\n have: %v \n want: %v", log, want)
+	}
+	if g.running() != 0 {
+		t.Errorf("\n have: %v \n want: %v", g.running(), 0)
+	}
+	wd.Stop()
+}
+
+func TestChanBuffered(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	ch := NewChan[int]("frames", 2)
+	sent := 0
+	g.NewCoroutine(func(co *C) {
+		for i := 1; i <= 4; i++ {
+			if done := ch.Send(co, i); done {
+				return
+			}
+			sent++
+		}
+		ch.Close()
+	})
+	if sent != 2 || ch.Len() != 2 {
+		t.Errorf("\n have: %v, %v \n want: %v, %v", sent, ch.Len(), 2, 2)
+	}
+
+	var received []int
+	g.NewCoroutine(func(co *C) {
+		for {
+			v, ok, _ := ch.Recv(co)
+			if !ok {
+				return
+			}
+			received = append(received, v)
+		}
+	})
+	g.Tick()
+	if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(received, want) {
+		t.Errorf("\n have: %v \n want: %v", received, want)
+	}
+	if !ch.Closed() || g.running() != 0 {
+		t.Errorf("\n have: %v, %v \n want: %v, %v", ch.Closed(), g.running(), true, 0)
+	}
+	wd.Stop()
+}
+
+func TestChanRecvUntil(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	ch := NewChan[string]("display", 0)
+	var ok, done bool
+	finished := false
+	g.NewCoroutine(func(co *C) {
+		_, ok, done = ch.RecvUntil(co, 1*time.Second)
+		finished = true
+	})
+	clk.Add(500 * time.Millisecond)
+	g.Tick()
+	if finished {
+		t.Fatalf("finished too early")
+	}
+	clk.Add(600 * time.Millisecond)
+	g.Tick()
+	if !finished || ok || done {
+		t.Errorf("\n have: %v, %v, %v \n want: %v, %v, %v", finished, ok, done, true, false, false)
+	}
+	wd.Stop()
+}
+
+func TestChanSendCanceled(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	ch := NewChan[int]("scores", 0)
+	canceled := false
+	cancel := g.NewCoroutine(func(co *C) {
+		canceled = ch.Send(co, 1)
+	})
+	if s := g.Snapshot()[0]; s.Cond != "Send(scores)" {
+		t.Errorf("\n have: %v \n want: %v", s.Cond, "Send(scores)")
+	}
+	cancel()
+	if !canceled {
+		t.Errorf("\n have: %v \n want: %v", canceled, true)
+	}
+
+	// The canceled send is withdrawn
+	var ok bool
+	g.NewCoroutine(func(co *C) {
+		_, ok, _ = ch.RecvUntil(co, 0)
+	})
+	if ok {
+		t.Errorf("\n have: %v \n want: %v", ok, false)
+	}
+	wd.Stop()
+}
+
+func TestChanCloseWithSender(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	g.SetPanicHandler(nil)
+	ch := NewChan[int]("scores", 0)
+	h := g.Spawn(func(co *C) {
+		ch.Send(co, 1)
+	})
+	ch.Close()
+	g.Tick()
+	if h.Result().Reason != Panicked {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Reason, Panicked)
+	}
+	wd.Stop()
+}
@@ -0,0 +1,269 @@
+package coroutine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type condOp int
+
+const (
+	condEvent condOp = iota
+	condMatch
+	condTimeout
+	condDone
+	condAllOf
+	condAnyOf
+	condReady
+)
+
+// Cond is a condition that a coroutine can wait for with Wait. Conditions
+// are created with On, OnMatch, Timeout, Done, AllOf and AnyOf.
+type Cond struct {
+	op     condOp
+	events []Event
+	match  Matcher
+	d      time.Duration
+	join   *C
+	conds  []Cond
+	name   string
+	ready  func() bool
+}
+
+// On is satisfied when an event is received that matches the key of any of
+// the given events.
+func On(events ...Event) Cond {
+	return Cond{op: condEvent, events: events}
+}
+
+// OnMatch is satisfied when an event is received that the matcher accepts.
+// Matching by key with On is faster and should be used when possible.
+func OnMatch(m Matcher) Cond {
+	return Cond{op: condMatch, match: m}
+}
+
+// Timeout is satisfied once the duration has elapsed from the start of the
+// wait.
+func Timeout(d time.Duration) Cond {
+	return Cond{op: condTimeout, d: d}
+}
+
+// Done is satisfied once the coroutine referred to by the handle has exited.
+func Done(h *Handle) Cond {
+	return Cond{op: condDone, join: h.co}
+}
+
+// until is satisfied once the function returns true. The function is
+// checked each time the scheduler looks for coroutines to resume. The name
+// describes the condition when inspecting.
+func until(name string, ready func() bool) Cond {
+	return Cond{op: condReady, name: name, ready: ready}
+}
+
+// AllOf is satisfied once all of the conditions have been satisfied, in any
+// order.
+func AllOf(conds ...Cond) Cond {
+	return Cond{op: condAllOf, conds: conds}
+}
+
+// AnyOf is satisfied as soon as one of the conditions has been satisfied.
+func AnyOf(conds ...Cond) Cond {
+	return Cond{op: condAnyOf, conds: conds}
+}
+
+// wait tracks the progress of a coroutine waiting on a condition. Each
+// node in the tree corresponds to a condition. Once a node has been
+// satisfied it remains so until the wait is over.
+type wait struct {
+	cond     Cond
+	met      bool
+	started  time.Time
+	expires  time.Time
+	children []*wait
+}
+
+func newWait(cond Cond, now time.Time) *wait {
+	w := &wait{cond: cond, started: now}
+	switch cond.op {
+	case condTimeout:
+		w.expires = now.Add(cond.d)
+	case condAllOf, condAnyOf:
+		w.children = make([]*wait, len(cond.conds))
+		for i, c := range cond.conds {
+			w.children[i] = newWait(c, now)
+		}
+	}
+	w.update(func(*wait) bool { return false })
+	return w
+}
+
+// update marks the leaf nodes for which fn returns true as met and then
+// reevaluates the tree. Returns true if the whole condition has been met.
+func (w *wait) update(fn func(*wait) bool) bool {
+	if w.met {
+		return true
+	}
+	switch w.cond.op {
+	case condAllOf:
+		met := true
+		for _, child := range w.children {
+			if !child.update(fn) {
+				met = false
+			}
+		}
+		w.met = met
+	case condAnyOf:
+		for _, child := range w.children {
+			if child.update(fn) {
+				w.met = true
+			}
+		}
+	case condDone:
+		w.met = w.cond.join.done
+	case condReady:
+		w.met = w.cond.ready()
+	default:
+		w.met = fn(w)
+	}
+	return w.met
+}
+
+func (w *wait) expire(now time.Time) bool {
+	return w.update(func(leaf *wait) bool {
+		return leaf.cond.op == condTimeout && now.After(leaf.expires)
+	})
+}
+
+// deliver offers the event to the tree. Returns true for met if the whole
+// condition has now been met and true for accepted if any part of the
+// condition was waiting for the event.
+func (w *wait) deliver(evt Event, key interface{}) (met bool, accepted bool) {
+	met = w.update(func(leaf *wait) bool {
+		switch leaf.cond.op {
+		case condEvent:
+			for _, evtReq := range leaf.cond.events {
+				if key == evtReq.Key() {
+					accepted = true
+					return true
+				}
+			}
+		case condMatch:
+			if leaf.cond.match.Match(evt) {
+				accepted = true
+				return true
+			}
+		}
+		return false
+	})
+	return met, accepted
+}
+
+// keys returns the keys of all events in the tree.
+func (w *wait) keys() []interface{} {
+	var keys []interface{}
+	w.visit(func(w *wait) {
+		if w.cond.op == condEvent {
+			for _, evt := range w.cond.events {
+				keys = append(keys, evt.Key())
+			}
+		}
+	})
+	return keys
+}
+
+// deadline returns the earliest time of any timeout in the tree that has
+// not yet been met.
+func (w *wait) deadline() time.Time {
+	var t time.Time
+	w.visit(func(w *wait) {
+		if w.cond.op == condTimeout && !w.met && (t.IsZero() || w.expires.Before(t)) {
+			t = w.expires
+		}
+	})
+	return t
+}
+
+// shift moves all timeouts in the tree later by the duration.
+func (w *wait) shift(d time.Duration) {
+	w.visit(func(w *wait) {
+		if w.cond.op == condTimeout {
+			w.expires = w.expires.Add(d)
+		}
+	})
+}
+
+// visit calls fn for every node in the tree.
+func (w *wait) visit(fn func(*wait)) {
+	fn(w)
+	for _, child := range w.children {
+		child.visit(fn)
+	}
+}
+
+// joined reevaluates the conditions that depend on the state of other
+// coroutines, such as Done, and returns true if the whole condition has
+// been met.
+func (w *wait) joined() bool {
+	return w.update(func(*wait) bool { return false })
+}
+
+// Wait yields until the condition has been satisfied. The event returned is
+// the one that completed the condition or nil if it was completed by a
+// timeout or by a coroutine exiting.
+func (c *C) Wait(cond Cond) (Event, bool) {
+	return c.waitOn(newWait(cond, c.group.Now()))
+}
+
+// waitOn yields until the wait has been met. The wait is kept up to date
+// while the coroutine is paused so the caller can inspect its timeouts
+// afterwards.
+func (c *C) waitOn(w *wait) (Event, bool) {
+	if w.met {
+		return nil, false
+	}
+	c.yield <- request{valid: true, wait: w}
+	return c.waitForResume()
+}
+
+// String describes the condition. Conditions that have been met are marked
+// with an asterisk.
+func (w *wait) String() string {
+	var s string
+	switch w.cond.op {
+	case condEvent:
+		keys := make([]string, len(w.cond.events))
+		for i, evt := range w.cond.events {
+			keys[i] = fmt.Sprint(evt.Key())
+		}
+		s = fmt.Sprintf("On(%v)", strings.Join(keys, ", "))
+	case condMatch:
+		s = fmt.Sprintf("OnMatch(%T)", w.cond.match)
+	case condTimeout:
+		s = fmt.Sprintf("Timeout(until %v)", w.expires.Format("15:04:05.000"))
+	case condDone:
+		s = fmt.Sprintf("Done(%v)", w.cond.join.displayName())
+	case condReady:
+		s = w.cond.name
+	case condAllOf, condAnyOf:
+		conds := make([]string, len(w.children))
+		for i, child := range w.children {
+			conds[i] = child.String()
+		}
+		op := "AllOf"
+		if w.cond.op == condAnyOf {
+			op = "AnyOf"
+		}
+		s = fmt.Sprintf("%v(%v)", op, strings.Join(conds, ", "))
+	}
+	if w.met {
+		s += "*"
+	}
+	return s
+}
+
+// WaitForFunc yields until an event is received for which the function
+// returns true.
+func (c *C) WaitForFunc(fn func(Event) bool) (Event, bool) {
+	return c.Wait(OnMatch(MatchFunc(fn)))
+}
@@ -0,0 +1,243 @@
+package coroutine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllOf(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	a := 0
+	var have Event
+	cancel := g.NewCoroutine(func(co *C) {
+		evt, done := co.Wait(AllOf(
+			On(testEvent("target 1")),
+			On(testEvent("target 2")),
+			On(testEvent("target 3")),
+		))
+		if done {
+			return
+		}
+		have = evt
+		a = 1
+	})
+
+	g.Post(testEvent("target 3"))
+	g.Post(testEvent("target 1"))
+	g.Tick()
+	g.Post(testEvent("target 1"))
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	g.Post(testEvent("target 2"))
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	if have != testEvent("target 2") {
+		t.Errorf("\n have: %v \n want: %v", have, testEvent("target 2"))
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestAnyOf(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	var have []string
+	waitForJackpot := func(co *C) {
+		h := co.New(func(co *C) {
+			co.WaitFor(testEvent("hurry up"))
+		})
+		evt, done := co.Wait(AnyOf(
+			On(testEvent("jackpot")),
+			Done(h),
+			Timeout(20*time.Second),
+		))
+		switch {
+		case done:
+			have = append(have, "canceled")
+		case evt != nil:
+			have = append(have, "jackpot")
+		case h.Done():
+			have = append(have, "hurry up")
+		default:
+			have = append(have, "timeout")
+		}
+	}
+
+	g.NewCoroutine(waitForJackpot)
+	g.Post(testEvent("jackpot"))
+	g.Tick()
+
+	g.NewCoroutine(waitForJackpot)
+	g.Post(testEvent("hurry up"))
+	g.Tick()
+
+	g.NewCoroutine(waitForJackpot)
+	clk.Add(25 * time.Second)
+	g.Tick()
+
+	cancel := g.NewCoroutine(waitForJackpot)
+	cancel()
+
+	want := []string{"jackpot", "hurry up", "timeout", "canceled"}
+	if len(have) != len(want) {
+		t.Fatalf("\n have: %v \n want: %v", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
+
+func TestNestedCond(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	a := 0
+	cancel := g.NewCoroutine(func(co *C) {
+		_, done := co.Wait(AllOf(
+			Timeout(1*time.Second),
+			AnyOf(On(testEvent("left ramp")), On(testEvent("right ramp"))),
+		))
+		if done {
+			return
+		}
+		a = 1
+	})
+
+	g.Post(testEvent("right ramp"))
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	clk.Add(1500 * time.Millisecond)
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestWaitDone(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	a := 0
+	cancel := g.NewCoroutine(func(co *C) {
+		h := co.New(func(co *C) {})
+		if _, done := co.Wait(AllOf(Done(h))); done {
+			return
+		}
+		a = 1
+	})
+
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	cancel()
+	wd.Stop()
+}
+
+type scoreEvent int
+
+func (e scoreEvent) Key() interface{} {
+	return scoreEvent(0)
+}
+
+type minScore int
+
+func (m minScore) Match(evt Event) bool {
+	score, ok := evt.(scoreEvent)
+	return ok && score >= scoreEvent(m)
+}
+
+func TestWaitForFunc(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have []Event
+	cancel := g.NewCoroutine(func(co *C) {
+		for {
+			evt, done := co.WaitForFunc(func(evt Event) bool {
+				name, ok := evt.(testEvent)
+				return ok && strings.HasPrefix(string(name), "target_")
+			})
+			if done {
+				return
+			}
+			have = append(have, evt)
+		}
+	})
+
+	g.Post(testEvent("sling"))
+	g.Post(testEvent("target_1"))
+	g.Post(scoreEvent(10))
+	g.Post(testEvent("target_2"))
+	g.Tick()
+
+	want := []Event{testEvent("target_1"), testEvent("target_2")}
+	if len(have) != len(want) {
+		t.Fatalf("\n have: %v \n want: %v", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestOnMatch(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have []string
+	cancel := g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			for {
+				if _, done := co.WaitFor(scoreEvent(0)); done {
+					return
+				}
+				have = append(have, "any score")
+			}
+		})
+		co.New(func(co *C) {
+			for {
+				if _, done := co.Wait(OnMatch(minScore(1_000_000))); done {
+					return
+				}
+				have = append(have, "big score")
+			}
+		})
+		co.WaitFor(testEvent("end"))
+	})
+
+	g.Post(scoreEvent(500))
+	g.Post(scoreEvent(2_000_000))
+	g.Tick()
+
+	want := []string{"any score", "any score", "big score"}
+	if len(have) != len(want) {
+		t.Fatalf("\n have: %v \n want: %v", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+	cancel()
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
@@ -0,0 +1,89 @@
+package coroutine
+
+import (
+	"context"
+	"time"
+)
+
+type canceling struct {
+	co    *C
+	cause error
+}
+
+// NewCoroutineContext creates a new coroutine like NewCoroutine that is
+// also canceled when the context is done. The cause of the cancellation is
+// the error from the context.
+func (g *Group) NewCoroutineContext(ctx context.Context, fn func(*C), opts ...Option) CancelFunc {
+	return g.SpawnContext(ctx, fn, opts...).Cancel
+}
+
+// NewCoroutineContextCause is like NewCoroutineContext but returns a
+// function that cancels the coroutine with a cause.
+func (g *Group) NewCoroutineContextCause(ctx context.Context, fn func(*C), opts ...Option) CancelCauseFunc {
+	return g.SpawnContext(ctx, fn, opts...).CancelWith
+}
+
+// SpawnContext is like NewCoroutineContext but returns a handle.
+func (g *Group) SpawnContext(ctx context.Context, fn func(*C), opts ...Option) *Handle {
+	return g.spawn(nil, ctx, fn, opts)
+}
+
+// Context returns a context that is canceled when the coroutine is canceled
+// or exits. The context of a child coroutine carries the values of the
+// context of its parent but is not canceled when the parent exits, since a
+// child may outlive a parent that is not a top-level coroutine. A child is
+// canceled along with its parent so its context is too.
+func (c *C) Context() context.Context {
+	if c.ctx == nil {
+		parent := context.Background()
+		if c.parent != nil {
+			parent = valuesOnly{c.parent.Context()}
+		}
+		c.ctx, c.ctxCancel = context.WithCancel(parent)
+		if c.done || c.cause != nil {
+			c.ctxCancel()
+		}
+	}
+	return c.ctx
+}
+
+// valuesOnly is a context with the values of another context that is never
+// canceled.
+type valuesOnly struct {
+	context.Context
+}
+
+func (valuesOnly) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (valuesOnly) Done() <-chan struct{}       { return nil }
+func (valuesOnly) Err() error                  { return nil }
+
+// watchContext waits until either the outer context or the context of the
+// coroutine is done. The context of the coroutine is derived from the outer
+// context so this goroutine exits when the coroutine exits.
+func (g *Group) watchContext(co *C, ctx context.Context) {
+	<-co.ctx.Done()
+	if err := ctx.Err(); err != nil {
+		g.mu.Lock()
+		g.canceling = append(g.canceling, canceling{co: co, cause: err})
+		g.mu.Unlock()
+	}
+}
+
+// cancelDone cancels the coroutines whose outer contexts are done.
+func (g *Group) cancelDone() {
+	g.mu.Lock()
+	pending := g.canceling
+	g.canceling = nil
+	g.mu.Unlock()
+	for _, c := range pending {
+		c.co.cancel(c.cause)
+	}
+}
+
+func NewContext(ctx context.Context, fn func(*C), opts ...Option) CancelFunc {
+	return group.NewCoroutineContext(ctx, fn, opts...)
+}
+
+func NewContextCause(ctx context.Context, fn func(*C), opts ...Option) CancelCauseFunc {
+	return group.NewCoroutineContextCause(ctx, fn, opts...)
+}
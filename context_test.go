@@ -0,0 +1,149 @@
+package coroutine
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestContext(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var ctx, childCtx context.Context
+	var errDuringCleanup error
+	cancel := g.NewCoroutine(func(co *C) {
+		ctx = co.Context()
+		co.New(func(co *C) {
+			childCtx = co.Context()
+			co.WaitFor(testEvent("event"))
+		})
+		if _, done := co.WaitFor(testEvent("event")); done {
+			errDuringCleanup = ctx.Err()
+		}
+	})
+
+	if ctx.Err() != nil {
+		t.Errorf("\n have: %v \n want: %v", ctx.Err(), nil)
+	}
+	if childCtx.Err() != nil {
+		t.Errorf("\n have: %v \n want: %v", childCtx.Err(), nil)
+	}
+	cancel()
+	if errDuringCleanup != context.Canceled {
+		t.Errorf("\n have: %v \n want: %v", errDuringCleanup, context.Canceled)
+	}
+	if childCtx.Err() != context.Canceled {
+		t.Errorf("\n have: %v \n want: %v", childCtx.Err(), context.Canceled)
+	}
+	wd.Stop()
+}
+
+func TestContextExit(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var ctx context.Context
+	cancel := g.NewCoroutine(func(co *C) {
+		ctx = co.Context()
+		co.WaitFor(testEvent("event"))
+	})
+
+	g.Post(testEvent("event"))
+	g.Tick()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("\n have: %v \n want: %v", ctx.Err(), context.Canceled)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestContextGrandchild(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	type key struct{}
+	outer := context.WithValue(context.Background(), key{}, "ball")
+	var ctx context.Context
+	h := g.SpawnContext(outer, func(co *C) {
+		co.New(func(co *C) {
+			co.New(func(co *C) {
+				ctx = co.Context()
+				co.WaitFor(testEvent("end"))
+			})
+			co.WaitFor(testEvent("exit"))
+		})
+		co.WaitFor(testEvent("end"))
+	})
+
+	// The child exits but the grandchild keeps running
+	g.Post(testEvent("exit"))
+	g.Tick()
+	if ctx.Err() != nil {
+		t.Errorf("\n have: %v \n want: %v", ctx.Err(), nil)
+	}
+	if v := ctx.Value(key{}); v != "ball" {
+		t.Errorf("\n have: %v \n want: %v", v, "ball")
+	}
+	g.Post(testEvent("end"))
+	g.Tick()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("\n have: %v \n want: %v", ctx.Err(), context.Canceled)
+	}
+	h.Cancel()
+	wd.Stop()
+}
+
+func TestNewCoroutineContext(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	var have, childHave error
+	h := g.SpawnContext(ctx, func(co *C) {
+		co.New(func(co *C) {
+			if _, done := co.WaitFor(testEvent("event")); done {
+				childHave = co.Cause()
+			}
+		})
+		if _, done := co.WaitFor(testEvent("event")); done {
+			have = co.Cause()
+		}
+	})
+
+	g.Tick()
+	if h.Done() {
+		t.Errorf("not expecting coroutine to be done")
+	}
+	ctxCancel()
+	for !h.Done() {
+		runtime.Gosched()
+		g.Tick()
+	}
+	if have != context.Canceled {
+		t.Errorf("\n have: %v \n want: %v", have, context.Canceled)
+	}
+	if childHave != context.Canceled {
+		t.Errorf("\n have: %v \n want: %v", childHave, context.Canceled)
+	}
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
+
+func TestNewCoroutineContextCause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	errTilt := errors.New("tilt")
+	var have error
+	cancel := g.NewCoroutineContextCause(context.Background(), func(co *C) {
+		if _, done := co.WaitFor(testEvent("event")); done {
+			have = co.Cause()
+		}
+	})
+	cancel(errTilt)
+	if have != errTilt {
+		t.Errorf("\n have: %v \n want: %v", have, errTilt)
+	}
+	wd.Stop()
+}
@@ -1,6 +1,11 @@
 package coroutine
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -10,117 +15,238 @@ type Event interface {
 	Key() interface{}
 }
 
+// Matcher is used to wait for events that cannot be found by key alone.
+type Matcher interface {
+	Match(evt Event) bool
+}
+
+type MatchFunc func(Event) bool
+
+func (f MatchFunc) Match(evt Event) bool {
+	return f(evt)
+}
+
+var (
+	// ErrCanceled is the cause given when a coroutine is canceled without
+	// one.
+	ErrCanceled = errors.New("coroutine canceled")
+
+	// ErrStopped is the cause given when a coroutine is canceled by Stop.
+	ErrStopped = errors.New("coroutine group stopped")
+
+	// ErrParentExited is the cause given when a coroutine is canceled
+	// because the top-level coroutine it was created from has exited.
+	ErrParentExited = errors.New("parent coroutine exited")
+)
+
+// DefaultMaxCascade is the maximum depth of events posted in reaction to
+// other events in a single tick before Tick panics.
+const DefaultMaxCascade = 100
+
 type Group struct {
-	clock  clock.Clock
-	active []*C
-	queue  []Event
+	clock      clock.Clock
+	timeScale  timeScale
+	active     []*C
+	queue      []posted
+	ticking    bool
+	maxCascade int
+	index      index
+	onPanic    func(*Handle, *PanicError)
+	tracer     Tracer
+	nextID     int
+	unmatch    unmatch
+	consumed   bool
+	subs       subscriptions
+	nextSub    int
+
+	// Events can be posted from any goroutine so they are collected in the
+	// inbox and moved to the queue by Tick. Events posted with PostNext are
+	// held in deferred until the start of the next tick. Coroutines whose
+	// outer context is done are collected in canceling. Spawning counts
+	// the coroutines being started outside of a tick.
+	mu        sync.Mutex
+	inbox     []posted
+	deferred  []posted
+	depth     int
+	canceling []canceling
+	spawning  int
+	recorder  *Recorder
 }
 
 type C struct {
 	group      *Group
+	id         int
+	slot       int
+	priority   int
+	name       string
+	labels     map[string]string
+	parent     *C
 	children   []*C
 	yield      chan request
 	resume     chan response
 	requesting request
+	cause      error
+	done       bool
+	result     Result
+	pause      bool
+	pausedAt   time.Time
+	panicked   *PanicError
+	handle     *Handle
+	onExit     func(*C)
+	subs       []*subscription
+	mailbox    *mailbox
+	ctx        context.Context
+	ctxCancel  context.CancelFunc
 }
 
 type request struct {
-	valid   bool
-	cancel  bool
-	expires time.Time
-	events  []Event
+	valid bool
+	wait  *wait
+}
+
+// posted is an event waiting in the queue. The depth is the number of events
+// in the chain that lead up to this event being posted in the current tick.
+// Internal events were posted while the scheduler was running coroutines,
+// which post them again on replay.
+type posted struct {
+	evt      Event
+	depth    int
+	internal bool
 }
 
+// The cause is set when the coroutine has been canceled.
 type response struct {
 	timeout bool
-	cancel  bool
+	cause   error
 	event   Event
 }
 
 func NewGroup() *Group {
+	return NewGroupWithClock(clock.New())
+}
+
+// NewGroupWithClock creates a group that uses the clock for all timers. Use
+// a mock clock to test coroutines without waiting in real time.
+func NewGroupWithClock(clk clock.Clock) *Group {
 	return &Group{
-		clock:  clock.New(),
-		active: make([]*C, 0),
+		clock:      clk,
+		timeScale:  newTimeScale(clk.Now()),
+		active:     make([]*C, 0),
+		maxCascade: DefaultMaxCascade,
+		index:      newIndex(),
+		subs:       make(subscriptions),
+		onPanic:    logPanic,
 	}
 }
 
 func newMockGroup() (*Group, *clock.Mock) {
-	g := NewGroup()
 	mock := clock.NewMock()
-	g.clock = mock
-	return g, mock
+	return NewGroupWithClock(mock), mock
 }
 
+// CancelFunc cancels a coroutine and all of its children with a cause of
+// ErrCanceled.
 type CancelFunc func()
 
-func (g *Group) NewCoroutine(fn func(*C)) CancelFunc {
-	co := &C{
-		group:    g,
-		children: make([]*C, 0),
-		yield:    make(chan request),
-		resume:   make(chan response),
-	}
-	g.add(co)
+// CancelCauseFunc cancels a coroutine and all of its children with the
+// given cause.
+type CancelCauseFunc func(cause error)
 
-	cancelFunc := func() {
-		// Cancel the outstanding request. This will get cleaned up on the
-		// next call to Tick
-		co.cancel()
-
-		// Let the coroutines have a chance to clean up
-		g.Tick()
-	}
+func (g *Group) NewCoroutine(fn func(*C), opts ...Option) CancelFunc {
+	return g.Spawn(fn, opts...).Cancel
+}
 
-	go func() {
-		fn(co)
-		close(co.yield)
-		cancelFunc()
-	}()
+// NewCoroutineCause creates a new coroutine like NewCoroutine but returns a
+// function that cancels the coroutine with a cause.
+func (g *Group) NewCoroutineCause(fn func(*C), opts ...Option) CancelCauseFunc {
+	return g.Spawn(fn, opts...).CancelWith
+}
 
-	// Let the newly created coroutine reach its first yield
-	co.requesting = <-co.yield
+// Spawn creates a new coroutine like NewCoroutine but returns a handle that
+// can be used to cancel the coroutine or to wait for it to exit.
+func (g *Group) Spawn(fn func(*C), opts ...Option) *Handle {
+	return g.spawn(nil, nil, fn, opts)
+}
 
-	return cancelFunc
+// New creates a child coroutine that is canceled when this coroutine is
+// canceled.
+func (c *C) New(fn func(*C), opts ...Option) *Handle {
+	return c.group.spawn(c, nil, fn, opts)
 }
 
-func (c *C) New(fn func(*C)) {
+func (g *Group) spawn(parent *C, ctx context.Context, fn func(*C), opts []Option) *Handle {
 	co := &C{
-		group:    c.group,
+		group:    g,
+		parent:   parent,
 		children: make([]*C, 0),
 		yield:    make(chan request),
 		resume:   make(chan response),
 	}
-	c.group.add(co)
-	c.children = append(c.children, co)
+	co.handle = &Handle{co: co}
+	if parent != nil {
+		co.priority = parent.priority
+	}
+	for _, opt := range opts {
+		opt(co)
+	}
+	g.nextID++
+	co.id = g.nextID
+	g.add(co)
+	if parent != nil {
+		parent.children = append(parent.children, co)
+		if parent.paused() {
+			co.pausedAt = g.Now()
+		}
+	}
+	if ctx != nil {
+		co.ctx, co.ctxCancel = context.WithCancel(ctx)
+		go g.watchContext(co, ctx)
+	}
+	g.traceSpawn(co)
+
 	go func() {
-		fn(co)
-		close(co.yield)
+		defer close(co.yield)
+		if co.panicked = run(co, fn); co.panicked != nil {
+			for _, child := range co.children {
+				child.cancel(co.panicked)
+			}
+			return
+		}
+		// The children of a top-level coroutine are canceled on exit. This
+		// is done before closing the yield channel so that it happens while
+		// this coroutine is still the one running. The cancellations are
+		// processed by the Tick that resumed this coroutine.
+		if co.parent == nil {
+			for _, child := range co.children {
+				child.cancel(ErrParentExited)
+			}
+		}
 	}()
+
 	// Let the newly created coroutine reach its first yield
-	co.requesting = <-co.yield
+	g.setSpawning(1)
+	g.waitForYield(co)
+	g.setSpawning(-1)
+
+	return co.handle
 }
 
 func (c *C) Sleep(d time.Duration) bool {
-	expires := c.group.clock.Now().Add(d)
-	c.yield <- request{valid: true, expires: expires}
-	_, done := c.waitForResume()
+	_, done := c.Wait(Timeout(d))
 	return done
 }
 
 func (c *C) WaitFor(events ...Event) (Event, bool) {
-	c.yield <- request{valid: true, events: events}
-	return c.waitForResume()
+	return c.Wait(On(events...))
 }
 
 func (c *C) WaitForUntil(d time.Duration, events ...Event) (Event, bool) {
-	expires := c.group.clock.Now().Add(d)
-	c.yield <- request{valid: true, expires: expires, events: events}
-	return c.waitForResume()
+	return c.Wait(AnyOf(On(events...), Timeout(d)))
 }
 
 func (c *C) waitForResume() (Event, bool) {
 	response := <-c.resume
-	if response.cancel {
+	if response.cause != nil {
 		return nil, true
 	}
 	if response.timeout {
@@ -129,70 +255,225 @@ func (c *C) waitForResume() (Event, bool) {
 	return response.event, false
 }
 
-func (c *C) cancel() {
-	c.requesting.cancel = true
+// Err returns ErrCanceled if the coroutine has been canceled and nil
+// otherwise.
+func (c *C) Err() error {
+	if c.cause == nil {
+		return nil
+	}
+	return ErrCanceled
+}
+
+// Cause returns the reason the coroutine was canceled or nil if it has not
+// been canceled. Children are canceled with the same cause as their parent.
+func (c *C) Cause() error {
+	return c.cause
+}
+
+func (c *C) cancel(cause error) {
+	if c.done || c.cause != nil {
+		return
+	}
+	if cause == nil {
+		cause = ErrCanceled
+	}
+	c.cause = cause
+	if c.ctxCancel != nil {
+		c.ctxCancel()
+	}
 	for _, co := range c.children {
-		co.cancel()
+		co.cancel(cause)
 	}
 }
 
+// resumeWith resumes the coroutine with the response and waits until it
+// yields again or exits.
+func (g *Group) resumeWith(co *C, r response) {
+	g.index.remove(co)
+	co.requesting = request{}
+	g.traceResume(co, r)
+	co.resume <- r
+	g.waitForYield(co)
+}
+
+// Coroutine is no longer active if the yield channel has been closed.
+// When closed, the channel returns a result where requesting.valid has the
+// default value of false.
+func (g *Group) waitForYield(co *C) {
+	co.requesting = <-co.yield
+	if !co.requesting.valid {
+		g.finish(co)
+		return
+	}
+	g.traceYield(co)
+	g.index.add(co)
+}
+
+// finish records the result of a coroutine that is no longer going to be
+// resumed and removes it from its parent.
+func (g *Group) finish(co *C) {
+	if co.done {
+		return
+	}
+	co.done = true
+	g.index.remove(co)
+	g.subs.removeAll(co)
+	if co.ctxCancel != nil {
+		co.ctxCancel()
+	}
+	co.result.Reason = Exited
+	if co.cause != nil {
+		co.result.Reason = Canceled
+		co.result.Err = co.cause
+	}
+	if co.panicked != nil {
+		co.result.Reason = Panicked
+		co.result.Err = co.panicked
+		if g.onPanic != nil {
+			g.onPanic(co.handle, co.panicked)
+		}
+	}
+	if co.parent != nil {
+		siblings := co.parent.children
+		for i, sibling := range siblings {
+			if sibling == co {
+				co.parent.children = append(siblings[:i], siblings[i+1:]...)
+				break
+			}
+		}
+	}
+	g.traceExit(co)
+	if co.onExit != nil {
+		co.onExit(co)
+	}
+}
+
+// Post queues an event for delivery. If called while Tick is running, such
+// as from within a coroutine, the event is delivered in the same tick after
+// all events already in the queue. Otherwise it is delivered on the next
+// call to Tick.
 func (g *Group) Post(evt Event) {
-	g.queue = append(g.queue, evt)
+	g.mu.Lock()
+	g.inbox = append(g.inbox, posted{evt: evt, depth: g.depth, internal: g.internal()})
+	g.mu.Unlock()
+}
+
+// PostNext queues an event for delivery on the next call to Tick even if
+// called while Tick is running.
+func (g *Group) PostNext(evt Event) {
+	g.mu.Lock()
+	p := posted{evt: evt, internal: g.internal()}
+	if g.ticking {
+		g.deferred = append(g.deferred, p)
+	} else {
+		g.inbox = append(g.inbox, p)
+	}
+	g.mu.Unlock()
+}
+
+// Post queues an event for delivery like Group.Post.
+func (c *C) Post(evt Event) {
+	c.group.Post(evt)
+}
+
+// PostNext queues an event for delivery like Group.PostNext.
+func (c *C) PostNext(evt Event) {
+	c.group.PostNext(evt)
+}
+
+// SetMaxCascade sets the maximum depth of events posted in reaction to other
+// events within a single tick. An event posted while delivering another
+// event is one deeper than that event. When the maximum is exceeded, Tick
+// panics as this is usually a sign of coroutines posting events back and
+// forth forever.
+func (g *Group) SetMaxCascade(n int) {
+	g.maxCascade = n
+}
+
+// drain moves all posted events into the queue and returns true if there
+// are events to be serviced.
+func (g *Group) drain() bool {
+	g.mu.Lock()
+	g.queue = append(g.queue, g.inbox...)
+	g.inbox = nil
+	g.mu.Unlock()
+	return len(g.queue) > 0
+}
+
+// setTicking marks the start or end of a tick. At the start, events
+// deferred from the previous tick are placed ahead of any others posted
+// since.
+func (g *Group) setTicking(ticking bool) {
+	g.mu.Lock()
+	g.ticking = ticking
+	if ticking {
+		g.inbox = append(g.deferred, g.inbox...)
+		g.deferred = nil
+	}
+	g.mu.Unlock()
+}
+
+// setDepth sets the cascade depth given to events posted from now on.
+func (g *Group) setDepth(depth int) {
+	g.mu.Lock()
+	g.depth = depth
+	g.mu.Unlock()
 }
 
+// Tick resumes all coroutines that have been canceled, that have timers
+// which have expired, or that are waiting for events that have been
+// posted. Calling Tick while a tick is already in progress, such as from a
+// CancelFunc invoked within a coroutine, does nothing since the tick in
+// progress handles the work.
 func (g *Group) Tick() {
-	now := g.clock.Now()
-	for i, co := range g.active {
+	if g.ticking {
+		return
+	}
+	g.setTicking(true)
+	defer g.setTicking(false)
+
+	g.recordTick()
+	g.drain()
+	g.cancelDone()
+	g.settle()
+
+	now := g.Now()
+	for _, co := range g.active {
 		if co == nil {
 			continue
 		}
 
-		// If the coroutine has been canceled, let it know so that it can
-		// cleanup.
-		if co.requesting.valid && co.requesting.cancel {
-			co.resume <- response{cancel: true}
-			co.requesting = <-co.yield
-			g.active[i] = nil
-		}
-
 		// Resume if requested timer has expired
-		expires := co.requesting.expires
-		if !expires.IsZero() && now.After(expires) {
-			co.resume <- response{timeout: true}
-			co.requesting = <-co.yield
+		if co.requesting.valid && co.cause == nil && !co.paused() && co.requesting.wait.expire(now) {
+			g.resumeWith(co, response{timeout: true})
 		}
 	}
-
-	// Service the queue
-	for len(g.queue) > 0 {
-		var evt Event
-		evt, g.queue = g.queue[0], g.queue[1:]
-
-		for _, co := range g.active {
-			if co == nil {
-				continue
-			}
-			// Resume if the requested event key matches
-			for _, evtReq := range co.requesting.events {
-				if evt.Key() == evtReq.Key() {
-					co.resume <- response{event: evt}
-					co.requesting = <-co.yield
-					break
-				}
-			}
+	g.settle()
+	g.redeliver(now)
+
+	// Service the queue. Events posted while servicing are delivered in
+	// this tick too.
+	for len(g.queue) > 0 || g.drain() {
+		var next posted
+		next, g.queue = g.queue[0], g.queue[1:]
+		if next.depth > g.maxCascade {
+			g.queue = nil
+			g.setDepth(0)
+			panic(fmt.Sprintf("event cascade exceeded maximum depth of %v: %v", g.maxCascade, next.evt.Key()))
 		}
+		g.setDepth(next.depth + 1)
+		if !g.deliver(next.evt) {
+			g.traceUnmatched(next.evt)
+			g.unmatched(next.evt)
+		}
+		g.settle()
+		g.setDepth(0)
 	}
 
-	// Coroutine is no longer active if the yield channel has been closed.
-	// When closed, the channel returns a result where
-	// requesting.valid has the default value of false
+	// Remove coroutines that have exited
 	for i, co := range g.active {
-		if co == nil {
-			continue
-		}
-		if !co.requesting.valid {
+		if co != nil && co.done {
 			g.active[i] = nil
-			continue
 		}
 	}
 
@@ -201,6 +482,7 @@ func (g *Group) Tick() {
 	for _, co := range g.active {
 		if co != nil {
 			g.active[i] = co
+			co.slot = i
 			i++
 		}
 	}
@@ -209,9 +491,89 @@ func (g *Group) Tick() {
 	}
 }
 
+// deliver calls the handlers subscribed to the event and resumes the
+// coroutines waiting for it, in priority order, until one consumes it.
+// Returns true if any handler or coroutine was waiting for it.
+func (g *Group) deliver(evt Event) bool {
+	key := evt.Key()
+	accepted := false
+	g.consumed = false
+	subs := g.subs.lookup(evt)
+
+	// Handlers are called ahead of coroutines with the same priority
+	notify := func(priority int) {
+		for len(subs) > 0 && subs[0].owner.priority >= priority && !g.consumed {
+			sub := subs[0]
+			subs = subs[1:]
+			if sub.active() {
+				accepted = true
+				if err := handle(sub, evt); err != nil {
+					if g.onPanic != nil {
+						g.onPanic(sub.owner.handle, err)
+					}
+					sub.owner.cancel(err)
+				}
+			}
+		}
+	}
+	for _, co := range g.index.lookup(key) {
+		if notify(co.priority); g.consumed {
+			return true
+		}
+		if co.done || !co.requesting.valid || co.cause != nil || co.paused() {
+			continue
+		}
+		// Resume if the requested event key matches
+		met, ok := co.requesting.wait.deliver(evt, key)
+		accepted = accepted || ok
+		if met {
+			g.resumeWith(co, response{event: evt})
+			if g.consumed {
+				return true
+			}
+		}
+	}
+	notify(math.MinInt)
+	return accepted
+}
+
+// settle resumes all coroutines that have been canceled and all
+// coroutines that are joining others that have exited or that are waiting
+// on a channel or lock that is now ready. This is repeated
+// until there are none left since resuming one coroutine may cause another
+// to be canceled or to exit.
+func (g *Group) settle() {
+	for {
+		found := false
+		for i, co := range g.active {
+			if co == nil || co.done {
+				continue
+			}
+			// If the coroutine has been canceled, let it know so that it can
+			// cleanup. It is not resumed again even if it yields.
+			if co.cause != nil {
+				g.resumeWith(co, response{cause: co.cause})
+				g.finish(co)
+				g.active[i] = nil
+				found = true
+				continue
+			}
+			if co.requesting.valid && !co.paused() && co.requesting.wait.joined() {
+				g.resumeWith(co, response{})
+				found = true
+			}
+		}
+		if !found {
+			return
+		}
+	}
+}
+
 func (g *Group) Stop() {
 	for _, co := range g.active {
-		co.cancel()
+		if co != nil {
+			co.cancel(ErrStopped)
+		}
 	}
 	g.Tick()
 }
@@ -223,16 +585,18 @@ func (g *Group) add(co *C) {
 	for i, active := range g.active {
 		if active == nil {
 			g.active[i] = co
+			co.slot = i
 			return
 		}
 	}
+	co.slot = len(g.active)
 	g.active = append(g.active, co)
 }
 
 func (g *Group) running() int {
 	n := 0
 	for _, active := range g.active {
-		if active != nil && active.requesting.valid {
+		if active != nil && !active.done {
 			n++
 		}
 	}
@@ -241,14 +605,26 @@ func (g *Group) running() int {
 
 var group = NewGroup()
 
-func New(fn func(*C)) CancelFunc {
-	return group.NewCoroutine(fn)
+func New(fn func(*C), opts ...Option) CancelFunc {
+	return group.NewCoroutine(fn, opts...)
+}
+
+func NewCause(fn func(*C), opts ...Option) CancelCauseFunc {
+	return group.NewCoroutineCause(fn, opts...)
+}
+
+func Spawn(fn func(*C), opts ...Option) *Handle {
+	return group.Spawn(fn, opts...)
 }
 
 func Post(evt Event) {
 	group.Post(evt)
 }
 
+func PostNext(evt Event) {
+	group.PostNext(evt)
+}
+
 func Tick() {
 	group.Tick()
 }
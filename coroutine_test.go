@@ -1,6 +1,8 @@
 package coroutine
 
 import (
+	"errors"
+	"sync"
 	"testing"
 	"time"
 )
@@ -399,3 +401,331 @@ func TestStop(t *testing.T) {
 	cancel()
 	wd.Stop()
 }
+
+func TestPostConcurrent(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	posters := 4
+	n := 100
+	a := 0
+	cancel := g.NewCoroutine(func(co *C) {
+		for {
+			if _, done := co.WaitFor(testEvent("event")); done {
+				return
+			}
+			a += 1
+		}
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(posters)
+	for i := 0; i < posters; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				g.Post(testEvent("event"))
+			}
+		}()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	running := true
+	for running {
+		select {
+		case <-finished:
+			running = false
+		default:
+		}
+		g.Tick()
+	}
+
+	if a != posters*n {
+		t.Errorf("\n have: %v \n want: %v", a, posters*n)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestPostWhileSleeping(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	a := 0
+	b := 0
+	cancel := g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			for {
+				if done := co.Sleep(10 * time.Millisecond); done {
+					return
+				}
+				b += 1
+			}
+		})
+		for {
+			if _, done := co.WaitFor(testEvent("event")); done {
+				return
+			}
+			a += 1
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 50; i++ {
+			g.Post(testEvent("event"))
+		}
+		close(done)
+	}()
+
+	for i := 0; i < 50; i++ {
+		clk.Add(20 * time.Millisecond)
+		g.Tick()
+	}
+	<-done
+	g.Tick()
+
+	if a != 50 {
+		t.Errorf("\n have: %v \n want: %v", a, 50)
+	}
+	if b != 50 {
+		t.Errorf("\n have: %v \n want: %v", b, 50)
+	}
+	cancel()
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
+
+func TestPostFromCoroutine(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have []Event
+	cancel := g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			if _, done := co.WaitFor(testEvent("a")); done {
+				return
+			}
+			g.Post(testEvent("c"))
+		})
+		for {
+			evt, done := co.WaitFor(testEvent("a"), testEvent("b"), testEvent("c"))
+			if done {
+				return
+			}
+			have = append(have, evt)
+		}
+	})
+
+	g.Post(testEvent("a"))
+	g.Post(testEvent("b"))
+	g.Tick()
+
+	want := []Event{testEvent("a"), testEvent("b"), testEvent("c")}
+	if len(have) != len(want) {
+		t.Fatalf("\n have: %v \n want: %v", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestPostNext(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	a := 0
+	cancel := g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			if _, done := co.WaitFor(testEvent("a")); done {
+				return
+			}
+			g.PostNext(testEvent("b"))
+		})
+		if _, done := co.WaitFor(testEvent("b")); done {
+			return
+		}
+		a = 1
+	})
+
+	g.Post(testEvent("a"))
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestMaxCascade(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	g.SetMaxCascade(10)
+	pingPong := func(recv testEvent, send testEvent) func(*C) {
+		return func(co *C) {
+			for {
+				if _, done := co.WaitFor(recv); done {
+					return
+				}
+				g.Post(send)
+			}
+		}
+	}
+	g.NewCoroutine(pingPong("ping", "pong"))
+	g.NewCoroutine(pingPong("pong", "ping"))
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expecting panic")
+		}
+		wd.Stop()
+	}()
+	g.Post(testEvent("ping"))
+	g.Tick()
+}
+
+func TestMaxCascadePost(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	tests := []struct {
+		name  string
+		group *Group
+		post  func(*C, Event)
+	}{
+		{"C.Post", NewGroup(), (*C).Post},
+		{"Group.Post", NewGroup(), func(co *C, evt Event) { co.group.Post(evt) }},
+		{"coroutine.Post", group, func(co *C, evt Event) { Post(evt) }},
+	}
+	for _, test := range tests {
+		g := test.group
+		g.SetMaxCascade(10)
+		pingPong := func(recv testEvent, send testEvent) func(*C) {
+			return func(co *C) {
+				for {
+					if _, done := co.WaitFor(recv); done {
+						return
+					}
+					test.post(co, send)
+				}
+			}
+		}
+		ping := g.Spawn(pingPong("ping", "pong"))
+		pong := g.Spawn(pingPong("pong", "ping"))
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("%v: expecting panic", test.name)
+				}
+			}()
+			g.Post(testEvent("ping"))
+			g.Tick()
+		}()
+		ping.Cancel()
+		pong.Cancel()
+		g.SetMaxCascade(DefaultMaxCascade)
+	}
+	wd.Stop()
+}
+
+func TestCancelCause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	errTilt := errors.New("tilt")
+	var parentErr, parentCause, childCause error
+	h := g.Spawn(func(co *C) {
+		co.New(func(co *C) {
+			if _, done := co.WaitFor(testEvent("event")); done {
+				childCause = co.Cause()
+			}
+		})
+		if _, done := co.WaitFor(testEvent("event")); done {
+			parentErr = co.Err()
+			parentCause = co.Cause()
+		}
+	})
+
+	h.CancelWith(errTilt)
+	if parentErr != ErrCanceled {
+		t.Errorf("\n have: %v \n want: %v", parentErr, ErrCanceled)
+	}
+	if parentCause != errTilt {
+		t.Errorf("\n have: %v \n want: %v", parentCause, errTilt)
+	}
+	if childCause != errTilt {
+		t.Errorf("\n have: %v \n want: %v", childCause, errTilt)
+	}
+	if h.Result().Err != errTilt {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Err, errTilt)
+	}
+
+	// The first cause is kept
+	h.CancelWith(ErrStopped)
+	if h.Result().Err != errTilt {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Err, errTilt)
+	}
+	wd.Stop()
+}
+
+func TestNewCoroutineCause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	errTilt := errors.New("tilt")
+	var have error
+	cancel := g.NewCoroutineCause(func(co *C) {
+		if _, done := co.WaitFor(testEvent("event")); done {
+			have = co.Cause()
+		}
+	})
+	cancel(errTilt)
+	if have != errTilt {
+		t.Errorf("\n have: %v \n want: %v", have, errTilt)
+	}
+	wd.Stop()
+}
+
+func TestStopCause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have error
+	g.NewCoroutine(func(co *C) {
+		if _, done := co.WaitFor(testEvent("event")); done {
+			have = co.Cause()
+		}
+	})
+
+	g.Stop()
+	if have != ErrStopped {
+		t.Errorf("\n have: %v \n want: %v", have, ErrStopped)
+	}
+	wd.Stop()
+}
+
+func TestParentExitedCause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have error
+	g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			if _, done := co.WaitFor(testEvent("event")); done {
+				have = co.Cause()
+			}
+		})
+	})
+
+	g.Tick()
+	if have != ErrParentExited {
+		t.Errorf("\n have: %v \n want: %v", have, ErrParentExited)
+	}
+	wd.Stop()
+}
@@ -0,0 +1,165 @@
+// Package coroutinetest provides a harness for testing coroutines without
+// waiting in real time.
+package coroutinetest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/drop-target-pinball/coroutine"
+)
+
+// DefaultFrameRate is the number of ticks per second.
+const DefaultFrameRate = 60
+
+type scripted struct {
+	at  time.Duration
+	evt coroutine.Event
+}
+
+// Harness runs a group of coroutines on a mock clock. Each tick advances the
+// clock by one frame. Events can be scripted to be posted at a given time.
+type Harness struct {
+	T      testing.TB
+	Group  *coroutine.Group
+	Clock  *clock.Mock
+	frame  time.Duration
+	start  time.Time
+	script []scripted
+}
+
+// New creates a harness that runs at DefaultFrameRate. All coroutines are
+// stopped when the test finishes.
+func New(t testing.TB) *Harness {
+	clk := clock.NewMock()
+	h := &Harness{
+		T:     t,
+		Group: coroutine.NewGroupWithClock(clk),
+		Clock: clk,
+		frame: time.Second / DefaultFrameRate,
+		start: clk.Now(),
+	}
+	t.Cleanup(h.Group.Stop)
+	return h
+}
+
+// SetFrameRate sets the number of ticks per second.
+func (h *Harness) SetFrameRate(fps int) {
+	h.frame = time.Second / time.Duration(fps)
+}
+
+// Spawn creates a top-level coroutine with the given name.
+func (h *Harness) Spawn(name string, fn func(*coroutine.C), opts ...coroutine.Option) *coroutine.Handle {
+	opts = append([]coroutine.Option{coroutine.WithName(name)}, opts...)
+	return h.Group.Spawn(fn, opts...)
+}
+
+// Post queues an event for delivery on the next tick.
+func (h *Harness) Post(evt coroutine.Event) {
+	h.Group.Post(evt)
+}
+
+// PostAt queues an event for delivery on the first tick at or after the
+// given time since the start of the harness.
+func (h *Harness) PostAt(at time.Duration, evt coroutine.Event) {
+	h.script = append(h.script, scripted{at: at, evt: evt})
+	sort.SliceStable(h.script, func(i, j int) bool {
+		return h.script[i].at < h.script[j].at
+	})
+}
+
+// Strict fails the test whenever an event is dropped because no coroutine
+// was waiting for it. If events are given, only events of the same types
+// are checked. This replaces the unmatched handler of the group.
+func (h *Harness) Strict(events ...coroutine.Event) {
+	types := make(map[reflect.Type]bool)
+	for _, evt := range events {
+		types[reflect.TypeOf(evt)] = true
+	}
+	h.Group.SetUnmatchedHandler(func(evt coroutine.Event) {
+		if len(types) > 0 && !types[reflect.TypeOf(evt)] {
+			return
+		}
+		h.T.Errorf("unmatched event: %v\n%v", evt.Key(), h.Dump())
+	})
+}
+
+// Elapsed returns the time since the start of the harness.
+func (h *Harness) Elapsed() time.Duration {
+	return h.Clock.Now().Sub(h.start)
+}
+
+// Tick advances the clock by n frames and ticks the group after each one.
+func (h *Harness) Tick(n int) {
+	for i := 0; i < n; i++ {
+		h.Clock.Add(h.frame)
+		for len(h.script) > 0 && h.script[0].at <= h.Elapsed() {
+			h.Group.Post(h.script[0].evt)
+			h.script = h.script[1:]
+		}
+		h.Group.Tick()
+	}
+}
+
+// Advance ticks until at least the duration has elapsed.
+func (h *Harness) Advance(d time.Duration) {
+	end := h.Elapsed() + d
+	for h.Elapsed() < end {
+		h.Tick(1)
+	}
+}
+
+// RunUntilDone ticks until the coroutine exits. If it has not exited by the
+// time the duration has elapsed, the test fails with a dump of all
+// coroutines.
+func (h *Harness) RunUntilDone(handle *coroutine.Handle, d time.Duration) {
+	h.T.Helper()
+	end := h.Elapsed() + d
+	for !handle.Done() {
+		if h.Elapsed() >= end {
+			h.T.Fatalf("coroutine stuck after %v\n%v", d, h.Dump())
+			return
+		}
+		h.Tick(1)
+	}
+}
+
+// AssertRunning fails the test if the coroutine has exited.
+func (h *Harness) AssertRunning(handle *coroutine.Handle) {
+	h.T.Helper()
+	if handle.Done() {
+		h.T.Errorf("expecting coroutine to be running\n%v", h.Dump())
+	}
+}
+
+// AssertDone fails the test if the coroutine has not exited.
+func (h *Harness) AssertDone(handle *coroutine.Handle) {
+	h.T.Helper()
+	if !handle.Done() {
+		h.T.Errorf("expecting coroutine to be done\n%v", h.Dump())
+	}
+}
+
+// AssertReason fails the test if the coroutine has not exited for the given
+// reason.
+func (h *Harness) AssertReason(handle *coroutine.Handle, reason coroutine.ExitReason) {
+	h.T.Helper()
+	if have := handle.Result().Reason; have != reason {
+		h.T.Errorf("\n have: %v \n want: %v\n%v", have, reason, h.Dump())
+	}
+}
+
+// Dump describes the state of all coroutines.
+func (h *Harness) Dump() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "at %v:\n", h.Elapsed())
+	for _, s := range h.Group.Snapshot() {
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package coroutinetest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drop-target-pinball/coroutine"
+)
+
+type testEvent string
+
+func (e testEvent) Key() interface{} {
+	return e
+}
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	*testing.T
+	failures []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestTick(t *testing.T) {
+	h := New(t)
+	h.SetFrameRate(10)
+	a := 0
+	co := h.Spawn("counter", func(co *coroutine.C) {
+		for a < 3 {
+			if done := co.Sleep(250 * time.Millisecond); done {
+				return
+			}
+			a += 1
+		}
+	})
+
+	h.Tick(3)
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	if h.Elapsed() != 300*time.Millisecond {
+		t.Errorf("\n have: %v \n want: %v", h.Elapsed(), 300*time.Millisecond)
+	}
+	h.AssertRunning(co)
+	h.Advance(600 * time.Millisecond)
+	if a != 3 {
+		t.Errorf("\n have: %v \n want: %v", a, 3)
+	}
+	h.AssertDone(co)
+	h.AssertReason(co, coroutine.Exited)
+}
+
+func TestPostAt(t *testing.T) {
+	h := New(t)
+	var have []time.Duration
+	h.Spawn("ramps", func(co *coroutine.C) {
+		for {
+			if _, done := co.WaitFor(testEvent("ramp")); done {
+				return
+			}
+			have = append(have, h.Elapsed())
+		}
+	})
+
+	h.PostAt(1*time.Second, testEvent("ramp"))
+	h.PostAt(500*time.Millisecond, testEvent("ramp"))
+	h.Advance(2 * time.Second)
+
+	want := []time.Duration{
+		h.frame * 30,
+		h.frame * 60,
+	}
+	if len(have) != len(want) {
+		t.Fatalf("\n have: %v \n want: %v", have, want)
+	}
+	for i := range want {
+		if have[i] < want[i] || have[i] > want[i]+h.frame {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+}
+
+func TestRunUntilDone(t *testing.T) {
+	h := New(t)
+	co := h.Spawn("hurry up", func(co *coroutine.C) {
+		co.WaitForUntil(5*time.Second, testEvent("jackpot"))
+	})
+	h.RunUntilDone(co, 10*time.Second)
+	h.AssertDone(co)
+}
+
+func TestStuck(t *testing.T) {
+	r := &recorder{T: t}
+	h := New(r)
+	co := h.Spawn("hurry up", func(co *coroutine.C) {
+		co.WaitFor(testEvent("jackpot"))
+	})
+	h.RunUntilDone(co, 1*time.Second)
+
+	if len(r.failures) != 1 {
+		t.Fatalf("\n have: %v \n want: %v", len(r.failures), 1)
+	}
+	want := "hurry up: waiting for On(jackpot)"
+	if !strings.Contains(r.failures[0], want) {
+		t.Errorf("\n have: %v \n want: %v", r.failures[0], want)
+	}
+}
+
+type scoreEvent int
+
+func (e scoreEvent) Key() interface{} {
+	return scoreEvent(0)
+}
+
+func TestStrict(t *testing.T) {
+	r := &recorder{T: t}
+	h := New(r)
+	h.Strict(testEvent(""))
+	h.Spawn("ramps", func(co *coroutine.C) {
+		for {
+			if _, done := co.WaitFor(testEvent("ramp")); done {
+				return
+			}
+		}
+	})
+	h.Post(testEvent("ramp"))
+	h.Post(testEvent("rmap"))
+	h.Post(scoreEvent(100))
+	h.Tick(1)
+
+	if len(r.failures) != 1 {
+		t.Fatalf("\n have: %v \n want: %v", len(r.failures), 1)
+	}
+	want := "unmatched event: rmap"
+	if !strings.Contains(r.failures[0], want) {
+		t.Errorf("\n have: %v \n want: %v", r.failures[0], want)
+	}
+}
@@ -82,11 +82,12 @@ func watchSlings(co *coroutine.C) {
 	fmt.Println("(+) watchSlings: start")
 	defer fmt.Println("(-) watchSlings: done")
 
-	s := coroutine.NewSequencer()
-	s.WaitFor(event("left sling"), event("right sling"))
-	s.Do(func() { awardScore(10) })
-	s.Loop()
-	s.Run(co)
+	co.Subscribe(func(coroutine.Event) {
+		awardScore(10)
+	}, event("left sling"), event("right sling"))
+
+	// The subscription is kept until canceled
+	co.WaitFor()
 }
 
 func watchStandups(co *coroutine.C) {
@@ -101,7 +102,7 @@ func watchStandups(co *coroutine.C) {
 	}
 
 	for {
-		evt, done := co.WaitFor(
+		sw, done := coroutine.WaitForType(co,
 			event("standup target #1"),
 			event("standup target #2"),
 			event("standup target #3"),
@@ -109,7 +110,6 @@ func watchStandups(co *coroutine.C) {
 		if done {
 			return
 		}
-		sw := evt.(event)
 		if lit[sw] {
 			awardScore(25)
 			bonus += 10
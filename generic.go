@@ -0,0 +1,62 @@
+package coroutine
+
+// OnType is satisfied when an event of type T is received.
+func OnType[T Event]() Cond {
+	return OnMatch(typeMatcher[T]())
+}
+
+func typeMatcher[T Event]() Matcher {
+	return MatchFunc(func(evt Event) bool {
+		_, ok := evt.(T)
+		return ok
+	})
+}
+
+// WaitForType yields until an event of type T is received that matches the
+// key of any of the given events and returns it as a T. If no events are
+// given, any event of type T is accepted. Events of other types that have
+// a matching key are ignored.
+func WaitForType[T Event](co *C, events ...T) (T, bool) {
+	cond := OnType[T]()
+	if len(events) > 0 {
+		cond = On(toEvents(events)...)
+	}
+	for {
+		evt, done := co.Wait(cond)
+		if done {
+			var zero T
+			return zero, true
+		}
+		if t, ok := evt.(T); ok {
+			return t, false
+		}
+	}
+}
+
+// SubscribeType calls the handler for every event of type T that matches
+// the key of any of the given events. If no events are given, the handler
+// is called for every event of type T. See Subscribe.
+func SubscribeType[T Event](co *C, fn func(T), events ...T) CancelFunc {
+	handler := func(evt Event) {
+		if t, ok := evt.(T); ok {
+			fn(t)
+		}
+	}
+	if len(events) == 0 {
+		return co.subscribe(&subscription{
+			owner: co,
+			keys:  []interface{}{matchAll{}},
+			match: typeMatcher[T](),
+			fn:    handler,
+		})
+	}
+	return co.Subscribe(handler, toEvents(events)...)
+}
+
+func toEvents[T Event](events []T) []Event {
+	evts := make([]Event, len(events))
+	for i, evt := range events {
+		evts[i] = evt
+	}
+	return evts
+}
@@ -0,0 +1,131 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// aliasEvent has the same keys as testEvent
+type aliasEvent string
+
+func (e aliasEvent) Key() interface{} {
+	return testEvent(e)
+}
+
+func TestWaitForType(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []testEvent
+	g.NewCoroutine(func(co *C) {
+		for {
+			evt, done := WaitForType(co, testEvent("ramp"), testEvent("orbit"))
+			if done {
+				return
+			}
+			have = append(have, evt)
+		}
+	})
+	g.Post(testEvent("ramp"))
+	g.Post(aliasEvent("ramp"))
+	g.Post(testEvent("orbit"))
+	g.Tick()
+
+	want := []testEvent{"ramp", "orbit"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestWaitForAnyType(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have scoreEvent
+	canceled := false
+	cancel := g.NewCoroutine(func(co *C) {
+		have, _ = WaitForType[scoreEvent](co)
+		_, canceled = WaitForType[scoreEvent](co)
+	})
+	g.Post(testEvent("ramp"))
+	g.Post(scoreEvent(100))
+	g.Tick()
+	if have != 100 {
+		t.Errorf("\n have: %v \n want: %v", have, 100)
+	}
+	cancel()
+	if !canceled {
+		t.Errorf("\n have: %v \n want: %v", canceled, true)
+	}
+	wd.Stop()
+}
+
+func TestOnType(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have Event
+	g.NewCoroutine(func(co *C) {
+		have, _ = co.Wait(AnyOf(OnType[scoreEvent](), Timeout(1*time.Second)))
+	})
+	g.Post(testEvent("ramp"))
+	g.Post(scoreEvent(5))
+	g.Tick()
+	if have != scoreEvent(5) {
+		t.Errorf("\n have: %v \n want: %v", have, scoreEvent(5))
+	}
+	wd.Stop()
+}
+
+func TestSubscribeType(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []testEvent
+	g.NewCoroutine(func(co *C) {
+		SubscribeType(co, func(evt testEvent) {
+			have = append(have, evt)
+		}, testEvent("sling"))
+		co.WaitFor()
+	})
+	g.Post(testEvent("sling"))
+	g.Post(aliasEvent("sling"))
+	g.Post(testEvent("sling"))
+	g.Tick()
+
+	want := []testEvent{"sling", "sling"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestSubscribeTypeAny(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []testEvent
+	g.NewCoroutine(func(co *C) {
+		SubscribeType(co, func(evt testEvent) {
+			have = append(have, evt)
+		})
+		co.WaitFor()
+	})
+	g.Post(testEvent("sling"))
+	g.Post(aliasEvent("sling"))
+	g.Post(testEvent("ramp"))
+	g.Post(scoreEvent(5))
+	g.Tick()
+
+	want := []testEvent{"sling", "ramp"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	if g.UnmatchedCount() != 2 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 2)
+	}
+	g.Stop()
+	if len(g.subs) != 0 {
+		t.Errorf("\n have: %v \n want: %v", len(g.subs), 0)
+	}
+	wd.Stop()
+}
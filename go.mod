@@ -1,5 +1,5 @@
 module github.com/drop-target-pinball/coroutine
 
-go 1.17
+go 1.18
 
 require github.com/benbjohnson/clock v1.3.0
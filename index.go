@@ -0,0 +1,76 @@
+package coroutine
+
+import (
+	"sort"
+)
+
+// index keeps track of which coroutines are waiting for which event keys
+// so that an event can be delivered without checking every coroutine.
+// Coroutines waiting with a Matcher have to be checked for every event.
+type index struct {
+	keys     map[interface{}]map[*C]struct{}
+	matchers map[*C]struct{}
+	// The keys each coroutine was added with so that they can be removed
+	// without walking the wait tree again.
+	added map[*C][]interface{}
+}
+
+func newIndex() index {
+	return index{
+		keys:     make(map[interface{}]map[*C]struct{}),
+		matchers: make(map[*C]struct{}),
+		added:    make(map[*C][]interface{}),
+	}
+}
+
+func (x index) add(co *C) {
+	keys := co.requesting.wait.keys()
+	co.requesting.wait.visit(func(w *wait) {
+		if w.cond.op == condMatch {
+			x.matchers[co] = struct{}{}
+		}
+	})
+	for _, key := range keys {
+		waiting, ok := x.keys[key]
+		if !ok {
+			waiting = make(map[*C]struct{})
+			x.keys[key] = waiting
+		}
+		waiting[co] = struct{}{}
+	}
+	x.added[co] = keys
+}
+
+func (x index) remove(co *C) {
+	for _, key := range x.added[co] {
+		waiting := x.keys[key]
+		delete(waiting, co)
+		if len(waiting) == 0 {
+			delete(x.keys, key)
+		}
+	}
+	delete(x.added, co)
+	delete(x.matchers, co)
+}
+
+// lookup returns the coroutines that may be waiting for an event with the
+// key. They are returned from highest to lowest priority and then in the
+// order they appear in the active list.
+func (x index) lookup(key interface{}) []*C {
+	var found []*C
+	for co := range x.keys[key] {
+		found = append(found, co)
+	}
+	for co := range x.matchers {
+		if _, ok := x.keys[key][co]; !ok {
+			found = append(found, co)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].priority != found[j].priority {
+			return found[i].priority > found[j].priority
+		}
+		return found[i].slot < found[j].slot
+	})
+	return found
+}
@@ -0,0 +1,211 @@
+package coroutine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Option configures a coroutine when it is created.
+type Option func(*C)
+
+// WithName gives a name to the coroutine that is used when inspecting the
+// state of coroutines.
+func WithName(name string) Option {
+	return func(c *C) {
+		c.name = name
+	}
+}
+
+// WithLabel adds a label to the coroutine that is used when inspecting the
+// state of coroutines.
+func WithLabel(key string, value string) Option {
+	return func(c *C) {
+		if c.labels == nil {
+			c.labels = make(map[string]string)
+		}
+		c.labels[key] = value
+	}
+}
+
+// Name returns the name of the coroutine or an empty string if it does not
+// have one.
+func (c *C) Name() string {
+	return c.name
+}
+
+// ID returns a number that identifies the coroutine within its group. IDs
+// are assigned in the order that coroutines are created, starting at one.
+func (h *Handle) ID() int {
+	return h.co.id
+}
+
+// Name returns the name of the coroutine or an empty string if it does not
+// have one.
+func (h *Handle) Name() string {
+	return h.co.name
+}
+
+func (c *C) displayName() string {
+	if c.name == "" {
+		return "<unnamed>"
+	}
+	return c.name
+}
+
+type State int
+
+const (
+	// StateRunning is the state of the coroutine that is currently
+	// executing.
+	StateRunning State = iota
+	// StateSleeping is the state of a coroutine that is only waiting for
+	// time to elapse.
+	StateSleeping
+	// StateWaiting is the state of a coroutine waiting for a condition.
+	StateWaiting
+	// StateCanceling is the state of a coroutine that has been canceled
+	// but has not yet been resumed to clean up.
+	StateCanceling
+	// StateDone is the state of a coroutine that has exited.
+	StateDone
+)
+
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateSleeping:
+		return "sleeping"
+	case StateWaiting:
+		return "waiting"
+	case StateCanceling:
+		return "canceling"
+	case StateDone:
+		return "done"
+	}
+	return "unknown"
+}
+
+// Snapshot is the state of a coroutine, and all of its children, at the
+// time the snapshot was taken.
+type Snapshot struct {
+	Name     string
+	Labels   map[string]string
+	Priority int
+	State    State
+	// The earliest time that the coroutine will be resumed if sleeping or
+	// waiting with a timeout.
+	Until time.Time
+	// The keys of the events the coroutine is waiting for.
+	Keys []interface{}
+	// A description of the condition the coroutine is waiting for. A
+	// coroutine waiting on a channel, lock or other primitive that was
+	// created with a name is shown with that name, as in Lock(speech).
+	Cond string
+	// True if the coroutine, or one of its ancestors, is paused.
+	Paused bool
+	// The cause if canceling.
+	Cause error
+	// The result if done.
+	Result   Result
+	Children []Snapshot
+}
+
+// Snapshot returns the state of all top-level coroutines and their
+// children. A coroutine that is still running after its parent has exited
+// is shown as a top-level coroutine. Snapshot must be called from the
+// goroutine that calls Tick or from within a coroutine.
+func (g *Group) Snapshot() []Snapshot {
+	snapshots := make([]Snapshot, 0)
+	for _, co := range g.active {
+		if co == nil {
+			continue
+		}
+		if co.parent == nil || (co.parent.done && !co.done) {
+			snapshots = append(snapshots, co.snapshot())
+		}
+	}
+	return snapshots
+}
+
+// Snapshot returns the state of the coroutine and its children.
+func (h *Handle) Snapshot() Snapshot {
+	return h.co.snapshot()
+}
+
+func (c *C) snapshot() Snapshot {
+	s := Snapshot{
+		Name:     c.name,
+		Priority: c.priority,
+		Paused:   c.paused(),
+		Children: make([]Snapshot, 0, len(c.children)),
+	}
+	if c.labels != nil {
+		s.Labels = make(map[string]string)
+		for k, v := range c.labels {
+			s.Labels[k] = v
+		}
+	}
+	switch {
+	case c.done:
+		s.State = StateDone
+		s.Result = c.result
+	case c.cause != nil:
+		s.State = StateCanceling
+		s.Cause = c.cause
+	case c.requesting.valid:
+		w := c.requesting.wait
+		s.State = StateWaiting
+		if w.cond.op == condTimeout {
+			s.State = StateSleeping
+		}
+		s.Until = w.deadline()
+		s.Keys = w.keys()
+		s.Cond = w.String()
+	}
+	for _, child := range c.children {
+		s.Children = append(s.Children, child.snapshot())
+	}
+	return s
+}
+
+// String describes the state of the coroutine and its children with one
+// line for each coroutine. Children are indented below their parent.
+func (s Snapshot) String() string {
+	var b strings.Builder
+	s.format(&b, 0)
+	return b.String()
+}
+
+func (s Snapshot) format(b *strings.Builder, depth int) {
+	name := s.Name
+	if name == "" {
+		name = "<unnamed>"
+	}
+	paused := ""
+	if s.Paused && s.State != StateDone {
+		paused = " (paused)"
+	}
+	fmt.Fprintf(b, "%v%v: %v%v\n", strings.Repeat("  ", depth), name, s.describe(), paused)
+	for _, child := range s.Children {
+		child.format(b, depth+1)
+	}
+}
+
+func (s Snapshot) describe() string {
+	switch s.State {
+	case StateSleeping:
+		return fmt.Sprintf("sleeping until %v", s.Until.Format("15:04:05.000"))
+	case StateWaiting:
+		return fmt.Sprintf("waiting for %v", s.Cond)
+	case StateCanceling:
+		return fmt.Sprintf("canceling: %v", s.Cause)
+	case StateDone:
+		if s.Result.Err != nil {
+			return fmt.Sprintf("%v: %v", s.Result.Reason, s.Result.Err)
+		}
+		return s.Result.Reason.String()
+	}
+	return s.State.String()
+}
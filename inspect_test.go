@@ -0,0 +1,134 @@
+package coroutine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	var inside Snapshot
+	cancel := g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			co.Sleep(5 * time.Second)
+		}, WithName("timer"))
+		co.New(func(co *C) {
+			co.WaitFor(testEvent("ramp"), testEvent("jackpot"))
+		}, WithName("shots"), WithLabel("mode", "hurryup"))
+		co.WaitFor(testEvent("snapshot"))
+		inside = g.Snapshot()[0]
+		co.WaitFor(testEvent("end"))
+	}, WithName("hurryup"))
+
+	snapshots := g.Snapshot()
+	if len(snapshots) != 1 {
+		t.Fatalf("\n have: %v \n want: %v", len(snapshots), 1)
+	}
+	have := snapshots[0]
+	if have.Name != "hurryup" {
+		t.Errorf("\n have: %v \n want: %v", have.Name, "hurryup")
+	}
+	if have.State != StateWaiting {
+		t.Errorf("\n have: %v \n want: %v", have.State, StateWaiting)
+	}
+	if len(have.Children) != 2 {
+		t.Fatalf("\n have: %v \n want: %v", len(have.Children), 2)
+	}
+
+	timer := have.Children[0]
+	if timer.State != StateSleeping {
+		t.Errorf("\n have: %v \n want: %v", timer.State, StateSleeping)
+	}
+	until := clk.Now().Add(5 * time.Second)
+	if !timer.Until.Equal(until) {
+		t.Errorf("\n have: %v \n want: %v", timer.Until, until)
+	}
+
+	shots := have.Children[1]
+	if shots.Labels["mode"] != "hurryup" {
+		t.Errorf("\n have: %v \n want: %v", shots.Labels["mode"], "hurryup")
+	}
+	keys := []interface{}{testEvent("ramp"), testEvent("jackpot")}
+	if len(shots.Keys) != len(keys) {
+		t.Fatalf("\n have: %v \n want: %v", shots.Keys, keys)
+	}
+	for i := range keys {
+		if shots.Keys[i] != keys[i] {
+			t.Errorf("\n have: %v \n want: %v", shots.Keys, keys)
+		}
+	}
+
+	want := "" +
+		"hurryup: waiting for On(snapshot)\n" +
+		"  timer: sleeping until 00:00:05.000\n" +
+		"  shots: waiting for On(ramp, jackpot)\n"
+	if have.String() != want {
+		t.Errorf("\n have: %v \n want: %v", have.String(), want)
+	}
+
+	g.Post(testEvent("snapshot"))
+	g.Tick()
+	if inside.State != StateRunning {
+		t.Errorf("\n have: %v \n want: %v", inside.State, StateRunning)
+	}
+
+	cancel()
+	snapshots = g.Snapshot()
+	if len(snapshots) != 0 {
+		t.Errorf("\n have: %v \n want: %v", len(snapshots), 0)
+	}
+	wd.Stop()
+}
+
+func TestSnapshotCanceling(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have Snapshot
+	cancel := g.NewCoroutine(func(co *C) {
+		child := co.New(func(co *C) {
+			co.WaitFor(testEvent("event"))
+		}, WithName("child"))
+		co.WaitFor(testEvent("cancel"))
+		child.Cancel()
+		have = child.Snapshot()
+		co.WaitFor(testEvent("end"))
+	})
+
+	g.Post(testEvent("cancel"))
+	g.Tick()
+	if have.State != StateCanceling {
+		t.Errorf("\n have: %v \n want: %v", have.State, StateCanceling)
+	}
+	if have.Cause != ErrCanceled {
+		t.Errorf("\n have: %v \n want: %v", have.Cause, ErrCanceled)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestSnapshotOrphan(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	cancel := g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			co.New(func(co *C) {
+				co.WaitFor(testEvent("end"))
+			}, WithName("grandchild"))
+			co.WaitFor(testEvent("exit"))
+		}, WithName("child"))
+		co.WaitFor(testEvent("end"))
+	}, WithName("parent"))
+
+	g.Post(testEvent("exit"))
+	g.Tick()
+	snapshots := g.Snapshot()
+	if len(snapshots) != 2 {
+		t.Fatalf("\n have: %v \n want: %v", len(snapshots), 2)
+	}
+	if snapshots[1].Name != "grandchild" {
+		t.Errorf("\n have: %v \n want: %v", snapshots[1].Name, "grandchild")
+	}
+	cancel()
+	wd.Stop()
+}
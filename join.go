@@ -0,0 +1,103 @@
+package coroutine
+
+import (
+	"fmt"
+	"time"
+)
+
+type ExitReason int
+
+const (
+	Running ExitReason = iota
+	Exited
+	Canceled
+	Panicked
+)
+
+func (r ExitReason) String() string {
+	switch r {
+	case Running:
+		return "running"
+	case Exited:
+		return "exited"
+	case Canceled:
+		return "canceled"
+	case Panicked:
+		return "panicked"
+	}
+	return "unknown"
+}
+
+// Result describes how a coroutine exited and the value, if any, it set
+// with SetResult. If the coroutine was canceled, Err is the cause. If the
+// coroutine panicked, Err is a *PanicError.
+type Result struct {
+	Reason ExitReason
+	Value  interface{}
+	Err    error
+}
+
+// Handle refers to a coroutine created with Spawn or New.
+type Handle struct {
+	co *C
+}
+
+// Cancel cancels the coroutine and all of its children with a cause of
+// ErrCanceled.
+func (h *Handle) Cancel() {
+	h.CancelWith(ErrCanceled)
+}
+
+// CancelWith cancels the coroutine and all of its children with the given
+// cause. If the coroutine has already been canceled, the original cause is
+// kept.
+func (h *Handle) CancelWith(cause error) {
+	// Cancel the outstanding request. This will get cleaned up on the
+	// next call to Tick
+	h.co.cancel(cause)
+
+	// Let the coroutines have a chance to clean up
+	h.co.group.Tick()
+}
+
+// String describes what the coroutine is doing: waiting for a condition,
+// being canceled, or how it exited.
+func (h *Handle) String() string {
+	s := h.co.snapshot()
+	return fmt.Sprintf("%v: %v", h.co.displayName(), s.describe())
+}
+
+// Done returns true if the coroutine is no longer running.
+func (h *Handle) Done() bool {
+	return h.co.done
+}
+
+// Result returns how the coroutine exited. The reason is Running if the
+// coroutine has not yet exited.
+func (h *Handle) Result() Result {
+	return h.co.result
+}
+
+// SetResult sets the value reported to those joining this coroutine once it
+// exits.
+func (c *C) SetResult(v interface{}) {
+	c.result.Value = v
+}
+
+// Join yields until the coroutine referred to by the handle exits and
+// returns its result.
+func (c *C) Join(h *Handle) (Result, bool) {
+	if _, done := c.Wait(Done(h)); done {
+		return Result{}, true
+	}
+	return h.co.result, false
+}
+
+// JoinUntil is like Join but gives up waiting after the duration has
+// elapsed. In that case, the reason in the result is Running.
+func (c *C) JoinUntil(d time.Duration, h *Handle) (Result, bool) {
+	if _, done := c.Wait(AnyOf(Done(h), Timeout(d))); done {
+		return Result{}, true
+	}
+	return h.co.result, false
+}
@@ -0,0 +1,148 @@
+package coroutine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJoin(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have Result
+	cancel := g.NewCoroutine(func(co *C) {
+		h := co.New(func(co *C) {
+			ramps := 0
+			for ramps < 3 {
+				if _, done := co.WaitFor(testEvent("ramp")); done {
+					return
+				}
+				ramps += 1
+			}
+			co.SetResult(ramps)
+		})
+		have, _ = co.Join(h)
+	})
+
+	g.Post(testEvent("ramp"))
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	if have.Reason != Running {
+		t.Errorf("\n have: %v \n want: %v", have.Reason, Running)
+	}
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	if have.Reason != Exited {
+		t.Errorf("\n have: %v \n want: %v", have.Reason, Exited)
+	}
+	if have.Value != 3 {
+		t.Errorf("\n have: %v \n want: %v", have.Value, 3)
+	}
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestJoinCanceled(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have Result
+	var child *Handle
+	cancel := g.NewCoroutine(func(co *C) {
+		child = co.New(func(co *C) {
+			co.WaitFor(testEvent("event"))
+		})
+		have, _ = co.Join(child)
+	})
+
+	g.Tick()
+	child.Cancel()
+	if have.Reason != Canceled {
+		t.Errorf("\n have: %v \n want: %v", have.Reason, Canceled)
+	}
+	if !child.Done() {
+		t.Errorf("expecting child to be done")
+	}
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestJoinExited(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var have Result
+	cancel := g.NewCoroutine(func(co *C) {
+		h := co.New(func(co *C) {
+			co.SetResult("done")
+		})
+		have, _ = co.Join(h)
+	})
+
+	if have.Value != "done" {
+		t.Errorf("\n have: %v \n want: %v", have.Value, "done")
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestJoinUntil(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	var have Result
+	joined := false
+	h := g.Spawn(func(co *C) {
+		child := co.New(func(co *C) {
+			co.WaitFor(testEvent("event"))
+		})
+		have, _ = co.JoinUntil(1*time.Second, child)
+		joined = true
+	})
+
+	clk.Add(1500 * time.Millisecond)
+	g.Tick()
+	if !joined {
+		t.Errorf("expecting join to time out")
+	}
+	if have.Reason != Running {
+		t.Errorf("\n have: %v \n want: %v", have.Reason, Running)
+	}
+	if !h.Done() {
+		t.Errorf("expecting coroutine to be done")
+	}
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
+
+func TestJoinCancelJoiner(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	joinDone := false
+	var child *Handle
+	h := g.Spawn(func(co *C) {
+		child = co.New(func(co *C) {
+			co.WaitFor(testEvent("event"))
+		})
+		_, joinDone = co.Join(child)
+	})
+
+	h.Cancel()
+	if !joinDone {
+		t.Errorf("expecting join to be canceled")
+	}
+	if h.Result().Reason != Canceled {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Reason, Canceled)
+	}
+	if child.Result().Reason != Canceled {
+		t.Errorf("\n have: %v \n want: %v", child.Result().Reason, Canceled)
+	}
+	wd.Stop()
+}
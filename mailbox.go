@@ -0,0 +1,111 @@
+package coroutine
+
+// Overflow determines what happens when an event arrives for a mailbox
+// that is full.
+type Overflow int
+
+const (
+	// DropNewest discards the event that arrived.
+	DropNewest Overflow = iota
+	// DropOldest discards the event that has been in the mailbox the
+	// longest to make room.
+	DropOldest
+)
+
+type mailbox struct {
+	events   []Event
+	waiting  []Event
+	size     int
+	overflow Overflow
+	dropped  int
+	cancel   CancelFunc
+}
+
+func (m *mailbox) put(evt Event) {
+	if len(m.events) >= m.size {
+		m.dropped++
+		if m.overflow == DropNewest || len(m.events) == 0 {
+			return
+		}
+		m.events = m.events[1:]
+	}
+	m.events = append(m.events, evt)
+}
+
+// Mailbox starts buffering every event that matches the key of any of the
+// given events so that none are missed while the coroutine is busy or
+// waiting for something else. Use Receive to take events from the mailbox
+// in the order they were delivered. At most size events are held and the
+// overflow policy decides which are discarded when full. Events are still
+// buffered while the coroutine is paused. Calling Mailbox
+// again changes the events and limits but keeps the events already held.
+// Panics if size is less than one.
+func (c *C) Mailbox(size int, overflow Overflow, events ...Event) {
+	if size < 1 {
+		panic("coroutine: non-positive mailbox size")
+	}
+	m := c.mailbox
+	if m == nil {
+		m = &mailbox{}
+		c.mailbox = m
+	} else {
+		m.cancel()
+	}
+	m.size = size
+	m.overflow = overflow
+	m.waiting = events
+	for len(m.events) > size {
+		m.events = m.events[1:]
+		m.dropped++
+	}
+	m.cancel = c.subscribe(&subscription{
+		owner:   c,
+		keys:    keysOf(events),
+		fn:      m.put,
+		buffers: true,
+	})
+}
+
+// Receive returns the oldest event in the mailbox. If the mailbox is empty,
+// Receive yields until an event arrives. Returns true if the coroutine has
+// been canceled. Panics if Mailbox has not been called.
+func (c *C) Receive() (Event, bool) {
+	m := c.mailbox
+	if m == nil {
+		panic("coroutine: Receive called without a mailbox")
+	}
+	if c.cause != nil {
+		return nil, true
+	}
+	for len(m.events) == 0 {
+		// The event is placed in the mailbox by the subscription before
+		// this coroutine is resumed. Events that arrived while paused are
+		// taken once resumed.
+		pending := until("Pending()", func() bool {
+			return len(m.events) > 0
+		})
+		if _, done := c.Wait(AnyOf(On(m.waiting...), pending)); done {
+			return nil, true
+		}
+	}
+	evt := m.events[0]
+	m.events = m.events[1:]
+	return evt, false
+}
+
+// Pending returns the number of events in the mailbox.
+func (c *C) Pending() int {
+	if c.mailbox == nil {
+		return 0
+	}
+	return len(c.mailbox.events)
+}
+
+// Dropped returns the number of events discarded because the mailbox was
+// full.
+func (c *C) Dropped() int {
+	if c.mailbox == nil {
+		return 0
+	}
+	return c.mailbox.dropped
+}
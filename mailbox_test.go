@@ -0,0 +1,167 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMailbox(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []Event
+	g.NewCoroutine(func(co *C) {
+		co.Mailbox(10, DropNewest, testEvent("a"), testEvent("b"))
+		for {
+			evt, done := co.Receive()
+			if done {
+				return
+			}
+			have = append(have, evt)
+			// Busy with something else while more events arrive
+			if _, done := co.WaitFor(testEvent("other")); done {
+				return
+			}
+		}
+	})
+	g.Post(testEvent("a"))
+	g.Post(testEvent("b"))
+	g.Post(testEvent("a"))
+	g.Tick()
+	want := []Event{testEvent("a")}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+
+	g.Post(testEvent("other"))
+	g.Post(testEvent("other"))
+	g.Post(testEvent("other"))
+	g.Tick()
+	want = []Event{testEvent("a"), testEvent("b"), testEvent("a")}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestMailboxOverflow(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	tests := []struct {
+		overflow Overflow
+		want     []Event
+	}{
+		{DropNewest, []Event{testEvent("1"), testEvent("2")}},
+		{DropOldest, []Event{testEvent("3"), testEvent("4")}},
+	}
+	for _, test := range tests {
+		g, _ := newMockGroup()
+		var co *C
+		g.NewCoroutine(func(c *C) {
+			co = c
+			co.Mailbox(2, test.overflow, testEvent("1"), testEvent("2"), testEvent("3"), testEvent("4"))
+			co.WaitFor(testEvent("end"))
+		})
+		for _, evt := range []testEvent{"1", "2", "3", "4"} {
+			g.Post(evt)
+		}
+		g.Tick()
+		if co.Pending() != 2 {
+			t.Errorf("\n have: %v \n want: %v", co.Pending(), 2)
+		}
+		if co.Dropped() != 2 {
+			t.Errorf("\n have: %v \n want: %v", co.Dropped(), 2)
+		}
+		if have := co.mailbox.events; !reflect.DeepEqual(have, test.want) {
+			t.Errorf("\n have: %v \n want: %v", have, test.want)
+		}
+		g.Stop()
+	}
+	wd.Stop()
+}
+
+func TestMailboxCancel(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	canceled := false
+	cancel := g.NewCoroutine(func(co *C) {
+		co.Mailbox(10, DropNewest, testEvent("a"))
+		_, canceled = co.Receive()
+	})
+	cancel()
+	if !canceled {
+		t.Errorf("\n have: %v \n want: %v", canceled, true)
+	}
+	wd.Stop()
+}
+
+func TestReceiveWithoutMailbox(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	g.SetPanicHandler(nil)
+	h := g.Spawn(func(co *C) {
+		co.Receive()
+	})
+	if h.Result().Reason != Panicked {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Reason, Panicked)
+	}
+	wd.Stop()
+}
+
+func TestMailboxZeroSize(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	g.SetPanicHandler(nil)
+	h := g.Spawn(func(co *C) {
+		co.Mailbox(0, DropOldest, testEvent("a"))
+		co.WaitFor(testEvent("end"))
+	})
+	if h.Result().Reason != Panicked {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Reason, Panicked)
+	}
+
+	// The group is not left with a subscription that panics
+	g.Post(testEvent("a"))
+	g.Tick()
+	wd.Stop()
+}
+
+func TestMailboxPaused(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []Event
+	var co *C
+	h := g.Spawn(func(c *C) {
+		co = c
+		co.Mailbox(10, DropNewest, testEvent("a"), testEvent("b"))
+		for {
+			evt, done := co.Receive()
+			if done {
+				return
+			}
+			have = append(have, evt)
+		}
+	})
+	h.Pause()
+	g.Post(testEvent("a"))
+	g.Post(testEvent("b"))
+	g.Tick()
+	if len(have) != 0 {
+		t.Errorf("\n have: %v \n want: %v", have, nil)
+	}
+	if co.Pending() != 2 {
+		t.Errorf("\n have: %v \n want: %v", co.Pending(), 2)
+	}
+	if g.UnmatchedCount() != 0 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 0)
+	}
+
+	h.Resume()
+	g.Tick()
+	want := []Event{testEvent("a"), testEvent("b")}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
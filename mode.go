@@ -0,0 +1,195 @@
+package coroutine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrUnknownMode is returned when starting a mode that has not been
+	// registered.
+	ErrUnknownMode = errors.New("unknown mode")
+
+	// ErrModeStopped is the cause given to a mode that is stopped by the
+	// mode manager.
+	ErrModeStopped = errors.New("mode stopped")
+)
+
+// Mode describes a mode that can be started by a ModeManager. Each time the
+// mode is started, Run is called in a new top-level coroutine. Everything
+// spawned by the mode is canceled when it stops.
+type Mode struct {
+	Name string
+	// Modes with a higher priority are above the others on the stack and
+	// receive events first.
+	Priority int
+	// Starting an exclusive mode stops all other active modes.
+	Exclusive bool
+	Run       func(*C)
+}
+
+// ModeStartedEvent is posted each time a mode is started.
+type ModeStartedEvent struct {
+	Name string
+}
+
+func (e ModeStartedEvent) Key() interface{} {
+	return e
+}
+
+// ModeStoppedEvent is posted each time a mode exits, either on its own or
+// by being stopped.
+type ModeStoppedEvent struct {
+	Name   string
+	Result Result
+}
+
+func (e ModeStoppedEvent) Key() interface{} {
+	return ModeStoppedEvent{Name: e.Name}
+}
+
+// ActiveMode describes a mode that is on the stack.
+type ActiveMode struct {
+	Name     string
+	Priority int
+	Started  time.Time
+	Handle   *Handle
+}
+
+type activeMode struct {
+	mode    Mode
+	handle  *Handle
+	started time.Time
+}
+
+// ModeManager starts and stops modes by name in a group. Active modes are
+// kept on a stack ordered by priority and the priority of each mode is
+// given to its coroutines so that events are delivered from the top of the
+// stack down.
+type ModeManager struct {
+	group *Group
+	modes map[string]Mode
+	stack []*activeMode
+}
+
+func NewModeManager(g *Group) *ModeManager {
+	return &ModeManager{
+		group: g,
+		modes: make(map[string]Mode),
+		stack: make([]*activeMode, 0),
+	}
+}
+
+// Register adds the mode to the registry. A mode registered with the same
+// name as another replaces it the next time it is started.
+func (m *ModeManager) Register(mode Mode) {
+	m.modes[mode.Name] = mode
+}
+
+// Start starts the mode with the given name. If the mode is already active
+// the handle of the running mode is returned.
+func (m *ModeManager) Start(name string) (*Handle, error) {
+	mode, ok := m.modes[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownMode, name)
+	}
+	if a := m.find(name); a != nil {
+		return a.handle, nil
+	}
+	if mode.Exclusive {
+		for len(m.stack) > 0 {
+			m.stop(m.stack[0])
+		}
+	}
+
+	a := &activeMode{mode: mode, started: m.group.Now()}
+	m.stack = append(m.stack, a)
+	sort.SliceStable(m.stack, func(i, j int) bool {
+		return m.stack[i].mode.Priority > m.stack[j].mode.Priority
+	})
+	m.group.Post(ModeStartedEvent{Name: name})
+	a.handle = m.group.Spawn(mode.Run,
+		WithName(name),
+		WithPriority(mode.Priority),
+		WithLabel("mode", name),
+		onExit(func(co *C) {
+			m.remove(a)
+			m.group.Post(ModeStoppedEvent{Name: name, Result: co.result})
+		}),
+	)
+	return a.handle, nil
+}
+
+// Stop cancels the mode with the given name, and everything it has
+// spawned, with ErrModeStopped as the cause. Returns false if the mode was
+// not active.
+func (m *ModeManager) Stop(name string) bool {
+	a := m.find(name)
+	if a == nil {
+		return false
+	}
+	m.stop(a)
+	return true
+}
+
+// Restart stops the mode, if active, and starts it again.
+func (m *ModeManager) Restart(name string) (*Handle, error) {
+	m.Stop(name)
+	return m.Start(name)
+}
+
+// IsActive returns true if the mode with the given name is on the stack.
+func (m *ModeManager) IsActive(name string) bool {
+	return m.find(name) != nil
+}
+
+// Active returns the modes on the stack from the highest priority to the
+// lowest. Modes with the same priority are in the order they were started.
+func (m *ModeManager) Active() []ActiveMode {
+	active := make([]ActiveMode, len(m.stack))
+	for i, a := range m.stack {
+		active[i] = ActiveMode{
+			Name:     a.mode.Name,
+			Priority: a.mode.Priority,
+			Started:  a.started,
+			Handle:   a.handle,
+		}
+	}
+	return active
+}
+
+// stop removes the mode from the stack right away, so that it can be
+// started again, even if it is canceled later in the tick.
+func (m *ModeManager) stop(a *activeMode) {
+	m.remove(a)
+	if a.handle != nil {
+		a.handle.CancelWith(ErrModeStopped)
+	}
+}
+
+func (m *ModeManager) find(name string) *activeMode {
+	for _, a := range m.stack {
+		if a.mode.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (m *ModeManager) remove(a *activeMode) {
+	for i, active := range m.stack {
+		if active == a {
+			m.stack = append(m.stack[:i], m.stack[i+1:]...)
+			return
+		}
+	}
+}
+
+// onExit sets a function that is called once the coroutine has finished.
+func onExit(fn func(*C)) Option {
+	return func(c *C) {
+		c.onExit = fn
+	}
+}
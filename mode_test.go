@@ -0,0 +1,219 @@
+package coroutine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func waitForever(co *C) {
+	co.WaitFor(testEvent("end"))
+}
+
+func activeNames(m *ModeManager) []string {
+	names := make([]string, 0)
+	for _, a := range m.Active() {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+func TestModeStack(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	m := NewModeManager(g)
+	var scored []string
+	scorer := func(name string, consume bool) func(*C) {
+		return func(co *C) {
+			for {
+				if _, done := co.WaitFor(testEvent("ramp")); done {
+					return
+				}
+				scored = append(scored, name)
+				if consume {
+					co.Consume()
+				}
+			}
+		}
+	}
+	m.Register(Mode{Name: "base", Priority: 1, Run: scorer("base", false)})
+	m.Register(Mode{Name: "bonus", Priority: 1, Run: scorer("bonus", false)})
+	m.Register(Mode{Name: "multiball", Priority: 10, Run: scorer("multiball", true)})
+	m.Start("base")
+	m.Start("multiball")
+	m.Start("bonus")
+
+	want := []string{"multiball", "base", "bonus"}
+	if have := activeNames(m); !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	m.Stop("multiball")
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	want = []string{"multiball", "base", "bonus"}
+	if !reflect.DeepEqual(scored, want) {
+		t.Errorf("\n have: %v \n want: %v", scored, want)
+	}
+	want = []string{"base", "bonus"}
+	if have := activeNames(m); !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestModeLifecycleEvents(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	m := NewModeManager(g)
+	m.Register(Mode{Name: "hurryup", Run: func(co *C) {
+		co.WaitFor(testEvent("jackpot"))
+	}})
+	var events []Event
+	g.NewCoroutine(func(co *C) {
+		for {
+			evt, done := co.Wait(AnyOf(
+				On(ModeStartedEvent{Name: "hurryup"}),
+				On(ModeStoppedEvent{Name: "hurryup"}),
+			))
+			if done {
+				return
+			}
+			events = append(events, evt)
+		}
+	})
+
+	h, err := m.Start("hurryup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Tick()
+	g.Post(testEvent("jackpot"))
+	g.Tick()
+
+	want := []Event{
+		ModeStartedEvent{Name: "hurryup"},
+		ModeStoppedEvent{Name: "hurryup", Result: Result{Reason: Exited}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("\n have: %v \n want: %v", events, want)
+	}
+	if !h.Done() {
+		t.Errorf("\n have: %v \n want: %v", h.Done(), true)
+	}
+	if m.IsActive("hurryup") {
+		t.Errorf("\n have: %v \n want: %v", m.IsActive("hurryup"), false)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestModeStopCleansUp(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	m := NewModeManager(g)
+	var child *Handle
+	m.Register(Mode{Name: "hurryup", Run: func(co *C) {
+		child = co.New(waitForever)
+		waitForever(co)
+	}})
+	h, _ := m.Start("hurryup")
+	if !m.Stop("hurryup") {
+		t.Errorf("\n have: %v \n want: %v", false, true)
+	}
+	if !errors.Is(h.Result().Err, ErrModeStopped) {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Err, ErrModeStopped)
+	}
+	if !errors.Is(child.Result().Err, ErrModeStopped) {
+		t.Errorf("\n have: %v \n want: %v", child.Result().Err, ErrModeStopped)
+	}
+	if m.Stop("hurryup") {
+		t.Errorf("\n have: %v \n want: %v", true, false)
+	}
+	wd.Stop()
+}
+
+func TestModeStartTwice(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	m := NewModeManager(g)
+	m.Register(Mode{Name: "base", Run: waitForever})
+	h1, _ := m.Start("base")
+	h2, _ := m.Start("base")
+	if h1 != h2 {
+		t.Errorf("\n have: %v \n want: %v", h2, h1)
+	}
+	h3, _ := m.Restart("base")
+	if h3 == h1 || !h1.Done() || h3.Done() {
+		t.Errorf("expecting new instance")
+	}
+	if g.running() != 1 {
+		t.Errorf("\n have: %v \n want: %v", g.running(), 1)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestModeExclusive(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	m := NewModeManager(g)
+	m.Register(Mode{Name: "base", Run: waitForever})
+	m.Register(Mode{Name: "skillshot", Run: waitForever})
+	m.Register(Mode{Name: "attract", Exclusive: true, Run: waitForever})
+	m.Start("base")
+	m.Start("skillshot")
+	m.Start("attract")
+
+	want := []string{"attract"}
+	if have := activeNames(m); !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	if g.running() != 1 {
+		t.Errorf("\n have: %v \n want: %v", g.running(), 1)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestModeUnknown(t *testing.T) {
+	g, _ := newMockGroup()
+	m := NewModeManager(g)
+	if _, err := m.Start("tilt"); !errors.Is(err, ErrUnknownMode) {
+		t.Errorf("\n have: %v \n want: %v", err, ErrUnknownMode)
+	}
+}
+
+func TestModeStartFromMode(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	m := NewModeManager(g)
+	m.Register(Mode{Name: "multiball", Priority: 10, Run: waitForever})
+	m.Register(Mode{Name: "base", Run: func(co *C) {
+		for {
+			if _, done := co.WaitFor(testEvent("lock")); done {
+				return
+			}
+			m.Restart("multiball")
+		}
+	}})
+	m.Start("base")
+	g.Post(testEvent("lock"))
+	g.Tick()
+	g.Post(testEvent("lock"))
+	g.Tick()
+
+	want := []string{"multiball", "base"}
+	if have := activeNames(m); !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	if g.running() != 2 {
+		t.Errorf("\n have: %v \n want: %v", g.running(), 2)
+	}
+	g.Stop()
+	wd.Stop()
+}
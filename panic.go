@@ -0,0 +1,54 @@
+package coroutine
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// PanicError records a panic that occurred in a coroutine.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("coroutine panic: %v", e.Value)
+}
+
+// SetPanicHandler sets the function called when a coroutine panics. The
+// panic does not stop the group: the children of the coroutine are
+// canceled with the *PanicError as the cause and all other coroutines
+// continue to run. A panic in a handler registered with Subscribe is also
+// reported here and cancels the coroutine that owns the subscription with
+// the *PanicError as the cause. The default handler logs the panic and its stack trace.
+// Set to nil to ignore panics.
+func (g *Group) SetPanicHandler(fn func(h *Handle, err *PanicError)) {
+	g.onPanic = fn
+}
+
+// run calls the coroutine function and returns an error if it panicked.
+func run(co *C, fn func(*C)) (err *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn(co)
+	return nil
+}
+
+// handle calls a subscription handler and returns an error if it panicked.
+func handle(sub *subscription, evt Event) (err *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	sub.fn(evt)
+	return nil
+}
+
+func logPanic(h *Handle, err *PanicError) {
+	log.Printf("%v\n%s", err, err.Stack)
+}
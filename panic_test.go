@@ -0,0 +1,145 @@
+package coroutine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanic(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var handled []*PanicError
+	g.SetPanicHandler(func(h *Handle, err *PanicError) {
+		handled = append(handled, err)
+	})
+
+	var have Result
+	var child *Handle
+	var grandchildCause error
+	a := 0
+	cancel := g.NewCoroutine(func(co *C) {
+		child = co.New(func(co *C) {
+			co.New(func(co *C) {
+				if _, done := co.WaitFor(testEvent("never")); done {
+					grandchildCause = co.Cause()
+				}
+			})
+			co.WaitFor(testEvent("boom"))
+			panic("boom")
+		})
+		have, _ = co.Join(child)
+	})
+	g.NewCoroutine(func(co *C) {
+		for {
+			if _, done := co.WaitFor(testEvent("event")); done {
+				return
+			}
+			a += 1
+		}
+	})
+
+	g.Post(testEvent("boom"))
+	g.Post(testEvent("event"))
+	g.Tick()
+
+	if have.Reason != Panicked {
+		t.Errorf("\n have: %v \n want: %v", have.Reason, Panicked)
+	}
+	err, ok := have.Err.(*PanicError)
+	if !ok {
+		t.Fatalf("\n have: %T \n want: %T", have.Err, err)
+	}
+	if err.Value != "boom" {
+		t.Errorf("\n have: %v \n want: %v", err.Value, "boom")
+	}
+	if !strings.Contains(string(err.Stack), "panic_test.go") {
+		t.Errorf("expecting stack trace to include test file")
+	}
+	if grandchildCause != err {
+		t.Errorf("\n have: %v \n want: %v", grandchildCause, err)
+	}
+	if len(handled) != 1 || handled[0] != err {
+		t.Errorf("\n have: %v \n want: %v", handled, []*PanicError{err})
+	}
+
+	g.Post(testEvent("event"))
+	g.Tick()
+	if a != 2 {
+		t.Errorf("\n have: %v \n want: %v", a, 2)
+	}
+	running := g.running()
+	if running != 1 {
+		t.Errorf("\n have: %v \n want: %v", running, 1)
+	}
+	cancel()
+	g.Stop()
+	wd.Stop()
+}
+
+func TestPanicOnStart(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	g.SetPanicHandler(nil)
+	h := g.Spawn(func(co *C) {
+		panic("boom")
+	})
+	if h.Result().Reason != Panicked {
+		t.Errorf("\n have: %v \n want: %v", h.Result().Reason, Panicked)
+	}
+	g.Tick()
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
+
+func TestPanicInHandler(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	var handled []*PanicError
+	g.SetPanicHandler(func(h *Handle, err *PanicError) {
+		handled = append(handled, err)
+	})
+
+	var cause error
+	h := g.Spawn(func(co *C) {
+		co.Subscribe(func(Event) {
+			panic("boom")
+		}, testEvent("boom"))
+		if _, done := co.WaitFor(); done {
+			cause = co.Cause()
+		}
+	})
+	a := 0
+	g.NewCoroutine(func(co *C) {
+		for {
+			if _, done := co.WaitFor(testEvent("boom")); done {
+				return
+			}
+			a += 1
+		}
+	})
+
+	g.Post(testEvent("boom"))
+	g.Tick()
+
+	if len(handled) != 1 {
+		t.Fatalf("\n have: %v \n want: %v", len(handled), 1)
+	}
+	if handled[0].Value != "boom" {
+		t.Errorf("\n have: %v \n want: %v", handled[0].Value, "boom")
+	}
+	if cause != handled[0] {
+		t.Errorf("\n have: %v \n want: %v", cause, handled[0])
+	}
+	if !h.Done() {
+		t.Errorf("expecting owner to be done")
+	}
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	g.Stop()
+	wd.Stop()
+}
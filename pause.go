@@ -0,0 +1,71 @@
+package coroutine
+
+import (
+	"time"
+)
+
+// Pause stops the coroutine and all of its children from being resumed
+// for timers, events, or joins until Resume is called. Time spent paused
+// does not count towards sleeps and timeouts. Paused coroutines can still
+// be canceled.
+func (h *Handle) Pause() {
+	co := h.co
+	if co.pause || co.done {
+		return
+	}
+	co.pause = true
+	now := co.group.Now()
+	co.visit(func(c *C) {
+		if c.pausedAt.IsZero() {
+			c.pausedAt = now
+		}
+	})
+}
+
+// Resume allows a paused coroutine and its children to be resumed again.
+// Children that have been paused on their own, or that have another
+// ancestor that is paused, remain paused.
+func (h *Handle) Resume() {
+	co := h.co
+	if !co.pause {
+		return
+	}
+	co.pause = false
+	now := co.group.Now()
+	co.visit(func(c *C) {
+		if c.pausedAt.IsZero() || c.paused() {
+			return
+		}
+		if c.requesting.valid {
+			from := c.pausedAt
+			if c.requesting.wait.started.After(from) {
+				from = c.requesting.wait.started
+			}
+			c.requesting.wait.shift(now.Sub(from))
+		}
+		c.pausedAt = time.Time{}
+	})
+}
+
+// Paused returns true if the coroutine, or any of its ancestors, is
+// paused.
+func (h *Handle) Paused() bool {
+	return h.co.paused()
+}
+
+func (c *C) paused() bool {
+	for co := c; co != nil; co = co.parent {
+		if co.pause {
+			return true
+		}
+	}
+	return false
+}
+
+// visit calls fn for the coroutine and all of its descendants.
+func (c *C) visit(fn func(*C)) {
+	fn(c)
+	for _, child := range c.children {
+		child.visit(fn)
+	}
+}
@@ -0,0 +1,133 @@
+package coroutine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	a := 0
+	h := g.Spawn(func(co *C) {
+		if done := co.Sleep(5 * time.Second); done {
+			return
+		}
+		a = 1
+	})
+
+	clk.Add(2 * time.Second)
+	g.Tick()
+	h.Pause()
+	if !h.Paused() {
+		t.Errorf("expecting coroutine to be paused")
+	}
+	clk.Add(10 * time.Second)
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+
+	h.Resume()
+	clk.Add(2500 * time.Millisecond)
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	clk.Add(1 * time.Second)
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	wd.Stop()
+}
+
+func TestPauseChildren(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	a := 0
+	b := 0
+	var child *Handle
+	h := g.Spawn(func(co *C) {
+		child = co.New(func(co *C) {
+			for {
+				if _, done := co.WaitFor(testEvent("event")); done {
+					return
+				}
+				b += 1
+			}
+		})
+		for {
+			if _, done := co.WaitFor(testEvent("event")); done {
+				return
+			}
+			a += 1
+		}
+	})
+
+	h.Pause()
+	g.Post(testEvent("event"))
+	g.Tick()
+	if a != 0 || b != 0 {
+		t.Errorf("\n have: %v \n want: %v", []int{a, b}, []int{0, 0})
+	}
+	if !child.Paused() {
+		t.Errorf("expecting child to be paused")
+	}
+
+	// The child remains paused on its own after the parent resumes
+	child.Pause()
+	h.Resume()
+	g.Post(testEvent("event"))
+	g.Tick()
+	if a != 1 || b != 0 {
+		t.Errorf("\n have: %v \n want: %v", []int{a, b}, []int{1, 0})
+	}
+
+	child.Resume()
+	g.Post(testEvent("event"))
+	g.Tick()
+	if a != 2 || b != 1 {
+		t.Errorf("\n have: %v \n want: %v", []int{a, b}, []int{2, 1})
+	}
+
+	h.Pause()
+	h.Cancel()
+	if !h.Done() || !child.Done() {
+		t.Errorf("expecting paused coroutines to be canceled")
+	}
+	wd.Stop()
+}
+
+func TestPauseJoin(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	a := 0
+	var joiner *Handle
+	cancel := g.NewCoroutine(func(co *C) {
+		child := co.New(func(co *C) {
+			co.WaitFor(testEvent("event"))
+		})
+		joiner = co.New(func(co *C) {
+			if _, done := co.Join(child); done {
+				return
+			}
+			a = 1
+		})
+		co.WaitFor(testEvent("end"))
+	})
+
+	joiner.Pause()
+	g.Post(testEvent("event"))
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	joiner.Resume()
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	cancel()
+	wd.Stop()
+}
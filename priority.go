@@ -0,0 +1,34 @@
+package coroutine
+
+// WithPriority sets the priority of the coroutine. When an event is
+// delivered, coroutines with a higher priority are resumed first.
+// Coroutines with the same priority are resumed in the order they were
+// created. Children have the same priority as their parent unless given
+// one.
+func WithPriority(p int) Option {
+	return func(c *C) {
+		c.priority = p
+	}
+}
+
+// Priority returns the priority of the coroutine.
+func (h *Handle) Priority() int {
+	return h.co.priority
+}
+
+// SetPriority changes the priority of the coroutine and all of its
+// children. The new priority is used for events delivered from now on.
+func (h *Handle) SetPriority(p int) {
+	h.co.visit(func(c *C) {
+		c.priority = p
+	})
+}
+
+// Consume stops the event that resumed the coroutine from being delivered
+// to any other coroutine that is waiting for it. Only coroutines later in
+// delivery order, with the same or a lower priority, are affected. It must
+// be called before yielding again and has no effect if the coroutine was
+// not resumed by an event.
+func (c *C) Consume() {
+	c.group.consumed = true
+}
@@ -0,0 +1,112 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPriorityOrder(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []string
+	waiter := func(name string) func(*C) {
+		return func(co *C) {
+			if _, done := co.WaitFor(testEvent("ramp")); done {
+				return
+			}
+			have = append(have, name)
+		}
+	}
+	g.NewCoroutine(waiter("base"))
+	g.NewCoroutine(waiter("multiball"), WithPriority(10))
+	g.NewCoroutine(waiter("bonus"))
+	g.NewCoroutine(waiter("attract"), WithPriority(-1))
+	g.Post(testEvent("ramp"))
+	g.Tick()
+
+	want := []string{"multiball", "base", "bonus", "attract"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	wd.Stop()
+}
+
+func TestConsume(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	base := 0
+	multiball := 0
+	g.NewCoroutine(func(co *C) {
+		for {
+			if _, done := co.WaitFor(testEvent("ramp")); done {
+				return
+			}
+			base++
+		}
+	})
+	mb := g.Spawn(func(co *C) {
+		for {
+			if _, done := co.WaitFor(testEvent("ramp")); done {
+				return
+			}
+			multiball++
+			co.Consume()
+		}
+	}, WithPriority(10))
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	if base != 0 || multiball != 1 {
+		t.Errorf("\n have: %v, %v \n want: %v, %v", base, multiball, 0, 1)
+	}
+
+	mb.Cancel()
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	if base != 1 || multiball != 1 {
+		t.Errorf("\n have: %v, %v \n want: %v, %v", base, multiball, 1, 1)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestConsumeNotUnmatched(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	g.NewCoroutine(func(co *C) {
+		co.WaitFor(testEvent("ramp"))
+		co.Consume()
+		co.WaitFor(testEvent("end"))
+	})
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	if g.UnmatchedCount() != 0 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 0)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestPriorityInherited(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var child *Handle
+	h := g.Spawn(func(co *C) {
+		child = co.New(func(co *C) {
+			co.WaitFor(testEvent("end"))
+		})
+		co.WaitFor(testEvent("end"))
+	}, WithPriority(5))
+	if child.Priority() != 5 {
+		t.Errorf("\n have: %v \n want: %v", child.Priority(), 5)
+	}
+	h.SetPriority(7)
+	if child.Priority() != 7 {
+		t.Errorf("\n have: %v \n want: %v", child.Priority(), 7)
+	}
+	if s := child.Snapshot(); s.Priority != 7 {
+		t.Errorf("\n have: %v \n want: %v", s.Priority, 7)
+	}
+	g.Stop()
+	wd.Stop()
+}
@@ -0,0 +1,157 @@
+package coroutine
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// recordHeader is written once at the start of a recording.
+type recordHeader struct {
+	Start time.Time
+}
+
+// recordTick is written for each tick with the clock time of the tick and
+// the events posted from outside of the coroutines since the last tick.
+type recordTick struct {
+	Time   time.Time
+	Events []Event
+}
+
+// Recorder writes the events posted to a group, and the time of each tick,
+// so that the exact sequence of coroutine activity can be reproduced with
+// Replay. Events are encoded with encoding/gob so each event type must be
+// registered with gob.Register and have exported fields.
+//
+// Only events posted from outside of the coroutines between ticks are
+// recorded. Events posted while a tick is running, or while a coroutine is
+// being started, are posted again when the coroutines run during the
+// replay. An event posted from another goroutine while a tick is running
+// is therefore not recorded.
+type Recorder struct {
+	enc *gob.Encoder
+	err error
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: gob.NewEncoder(w)}
+}
+
+// Err returns the first error encountered while writing.
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+func (r *Recorder) write(v interface{}) {
+	if r.err == nil {
+		r.err = r.enc.Encode(v)
+	}
+}
+
+// SetRecorder starts recording to the recorder. It should be set before
+// any coroutines are created. Set to nil to stop recording.
+func (g *Group) SetRecorder(r *Recorder) {
+	if r != nil {
+		r.write(recordHeader{Start: g.clock.Now()})
+	}
+	g.mu.Lock()
+	g.recorder = r
+	g.mu.Unlock()
+}
+
+// internal returns true if events posted now are posted by coroutines. Must
+// be called with the lock held.
+func (g *Group) internal() bool {
+	return g.ticking || g.spawning > 0
+}
+
+// recordTick writes the time of the tick and the events posted from outside
+// of the coroutines that are waiting to be delivered at the start of it.
+func (g *Group) recordTick() {
+	g.mu.Lock()
+	r := g.recorder
+	var events []Event
+	if r != nil {
+		for _, p := range g.inbox {
+			if !p.internal {
+				events = append(events, p.evt)
+			}
+		}
+	}
+	g.mu.Unlock()
+	if r != nil {
+		r.write(recordTick{Time: g.clock.Now(), Events: events})
+	}
+}
+
+func (g *Group) setSpawning(delta int) {
+	g.mu.Lock()
+	g.spawning += delta
+	g.mu.Unlock()
+}
+
+// Replay drives a group on a mock clock from a recording. Create the same
+// coroutines that were created at the start of the recording and then call
+// Run.
+type Replay struct {
+	dec   *gob.Decoder
+	group *Group
+	clock *clock.Mock
+}
+
+// NewReplay reads the start of the recording and creates a group with a mock
+// clock set to the time that the recording started.
+func NewReplay(r io.Reader) (*Replay, error) {
+	dec := gob.NewDecoder(r)
+	var header recordHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, err
+	}
+	clk := clock.NewMock()
+	clk.Set(header.Start)
+	return &Replay{
+		dec:   dec,
+		group: NewGroupWithClock(clk),
+		clock: clk,
+	}, nil
+}
+
+// Group returns the group driven by the replay.
+func (p *Replay) Group() *Group {
+	return p.group
+}
+
+// Clock returns the mock clock of the group.
+func (p *Replay) Clock() *clock.Mock {
+	return p.clock
+}
+
+// Step replays the next tick. Returns false at the end of the recording.
+func (p *Replay) Step() (bool, error) {
+	var tick recordTick
+	if err := p.dec.Decode(&tick); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	p.clock.Set(tick.Time)
+	for _, evt := range tick.Events {
+		p.group.Post(evt)
+	}
+	p.group.Tick()
+	return true, nil
+}
+
+// Run replays all remaining ticks.
+func (p *Replay) Run() error {
+	for {
+		more, err := p.Step()
+		if err != nil || !more {
+			return err
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package coroutine
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func init() {
+	gob.Register(testEvent(""))
+}
+
+func TestRecordReplay(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+
+	// Records what happens and when
+	game := func(log *[]string) func(*C) {
+		return func(co *C) {
+			co.New(func(co *C) {
+				for {
+					evt, done := co.WaitForUntil(700*time.Millisecond, testEvent("ramp"))
+					if done {
+						return
+					}
+					*log = append(*log, fmt.Sprintf("%v ramp %v", co.group.Now().UnixNano(), evt))
+					if evt != nil {
+						co.group.Post(testEvent("jackpot"))
+					}
+				}
+			})
+			for {
+				if _, done := co.WaitFor(testEvent("jackpot")); done {
+					return
+				}
+				*log = append(*log, fmt.Sprintf("%v jackpot", co.group.Now().UnixNano()))
+			}
+		}
+	}
+
+	var recorded []string
+	var buf bytes.Buffer
+	g, clk := newMockGroup()
+	r := NewRecorder(&buf)
+	g.SetRecorder(r)
+	g.NewCoroutine(game(&recorded))
+	steps := []time.Duration{100, 250, 16, 400, 33, 900, 17}
+	for i, step := range steps {
+		clk.Add(step * time.Millisecond)
+		if i%2 == 0 {
+			g.Post(testEvent("ramp"))
+		}
+		g.Tick()
+	}
+	g.SetRecorder(nil)
+	g.Stop()
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+
+	var replayed []string
+	p, err := NewReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Group().NewCoroutine(game(&replayed))
+	if err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorded) == 0 {
+		t.Fatalf("expecting activity to be recorded")
+	}
+	if len(replayed) != len(recorded) {
+		t.Fatalf("\n have: %v \n want: %v", replayed, recorded)
+	}
+	for i := range recorded {
+		if replayed[i] != recorded[i] {
+			t.Errorf("\n have: %v \n want: %v", replayed, recorded)
+		}
+	}
+	if !p.Clock().Now().Equal(clk.Now()) {
+		t.Errorf("\n have: %v \n want: %v", p.Clock().Now(), clk.Now())
+	}
+	wd.Stop()
+}
+
+func TestRecordPostFromCoroutine(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+
+	// Posts with the group, as coroutines that predate C.Post do, when
+	// started and in reaction to events
+	game := func(g *Group, log *[]string) func(*C) {
+		return func(co *C) {
+			g.Post(testEvent("start"))
+			for {
+				evt, done := co.WaitFor(testEvent("start"), testEvent("ramp"), testEvent("jackpot"), testEvent("bonus"))
+				if done {
+					return
+				}
+				*log = append(*log, fmt.Sprintf("%v %v", g.Now().UnixNano(), evt))
+				if evt == testEvent("ramp") {
+					g.Post(testEvent("jackpot"))
+					g.PostNext(testEvent("bonus"))
+				}
+			}
+		}
+	}
+
+	var recorded []string
+	var buf bytes.Buffer
+	g, clk := newMockGroup()
+	g.SetRecorder(NewRecorder(&buf))
+	g.NewCoroutine(game(g, &recorded))
+	clk.Add(100 * time.Millisecond)
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	clk.Add(100 * time.Millisecond)
+	g.Tick()
+	g.SetRecorder(nil)
+	g.Stop()
+
+	var replayed []string
+	p, err := NewReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Group().NewCoroutine(game(p.Group(), &replayed))
+	if err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"100000000 start",
+		"100000000 ramp",
+		"100000000 jackpot",
+		"200000000 bonus",
+	}
+	if !reflect.DeepEqual(recorded, want) {
+		t.Errorf("\n have: %v \n want: %v", recorded, want)
+	}
+	if !reflect.DeepEqual(replayed, want) {
+		t.Errorf("\n have: %v \n want: %v", replayed, want)
+	}
+	wd.Stop()
+}
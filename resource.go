@@ -0,0 +1,116 @@
+package coroutine
+
+import "fmt"
+
+type claim struct {
+	co       *C
+	priority int
+	seq      int
+}
+
+// Resource arbitrates ownership of a device, such as the display or the
+// flashers, between coroutines. The coroutine with the highest priority
+// claim owns the resource. When a higher priority claim is made, the owner
+// is preempted and becomes the owner again once the higher priority claim
+// is released. Claims of the same priority are granted in the order they
+// were made. A claim is released automatically when its coroutine is
+// canceled or exits, which includes being canceled along with a parent.
+type Resource struct {
+	name   string
+	claims []*claim
+	seq    int
+}
+
+// NewResource creates a resource without an owner.
+func NewResource(name string) *Resource {
+	return &Resource{name: name}
+}
+
+// Acquire claims the resource with the given priority and yields until the
+// coroutine is the owner. If the coroutine already has a claim, its
+// priority is changed. Returns true if the coroutine was canceled, in which
+// case the claim is released.
+func (r *Resource) Acquire(co *C, priority int) bool {
+	c := r.find(co)
+	if c == nil {
+		r.seq++
+		c = &claim{co: co, seq: r.seq}
+		r.claims = append(r.claims, c)
+	}
+	c.priority = priority
+	_, done := co.Wait(until(fmt.Sprintf("Acquire(%v)", r.name), func() bool {
+		return r.owner() == c
+	}))
+	if done {
+		r.Release(co)
+	}
+	return done
+}
+
+// Preempted is satisfied once the coroutine no longer owns the resource,
+// either because a higher priority claim was made or because the claim
+// was released. Use it with Wait while working with the resource and call
+// Acquire again to wait to get it back.
+func (r *Resource) Preempted(co *C) Cond {
+	return until(fmt.Sprintf("Preempted(%v)", r.name), func() bool {
+		return !r.Owns(co)
+	})
+}
+
+// Release withdraws the claim of the coroutine. If it was the owner, the
+// next claim in line becomes the owner.
+func (r *Resource) Release(co *C) {
+	for i, c := range r.claims {
+		if c.co == co {
+			r.claims = append(r.claims[:i], r.claims[i+1:]...)
+			return
+		}
+	}
+}
+
+// Owns returns true if the coroutine is the owner of the resource.
+func (r *Resource) Owns(co *C) bool {
+	c := r.owner()
+	return c != nil && c.co == co
+}
+
+// Owner returns the handle of the coroutine that owns the resource or nil
+// if there is no owner.
+func (r *Resource) Owner() *Handle {
+	c := r.owner()
+	if c == nil {
+		return nil
+	}
+	return c.co.handle
+}
+
+// owner returns the claim with the highest priority. Claims of coroutines
+// that have been canceled or have exited are dropped.
+func (r *Resource) owner() *claim {
+	var best *claim
+	live := r.claims[:0]
+	for _, c := range r.claims {
+		if c.co.done || c.co.cause != nil {
+			continue
+		}
+		live = append(live, c)
+		if best == nil || c.priority > best.priority ||
+			(c.priority == best.priority && c.seq < best.seq) {
+			best = c
+		}
+	}
+	for i := len(live); i < len(r.claims); i++ {
+		r.claims[i] = nil
+	}
+	r.claims = live
+	return best
+}
+
+func (r *Resource) find(co *C) *claim {
+	for _, c := range r.claims {
+		if c.co == co {
+			return c
+		}
+	}
+	return nil
+}
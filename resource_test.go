@@ -0,0 +1,124 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResourcePreempt(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	display := NewResource("display")
+	var log []string
+	g.NewCoroutine(func(co *C) {
+		for {
+			if done := display.Acquire(co, 0); done {
+				return
+			}
+			log = append(log, "score: owner")
+			if _, done := co.Wait(display.Preempted(co)); done {
+				return
+			}
+			log = append(log, "score: preempted")
+		}
+	}, WithName("score"))
+	g.NewCoroutine(func(co *C) {
+		co.WaitFor(testEvent("jackpot"))
+		if done := display.Acquire(co, 10); done {
+			return
+		}
+		log = append(log, "jackpot: owner")
+		co.Sleep(1 * time.Second)
+		display.Release(co)
+		co.WaitFor(testEvent("end"))
+	}, WithName("jackpot"))
+
+	if display.Owner().co.name != "score" {
+		t.Errorf("\n have: %v \n want: %v", display.Owner().co.name, "score")
+	}
+	g.Post(testEvent("jackpot"))
+	g.Tick()
+	if display.Owner().co.name != "jackpot" {
+		t.Errorf("\n have: %v \n want: %v", display.Owner().co.name, "jackpot")
+	}
+	clk.Add(1100 * time.Millisecond)
+	g.Tick()
+
+	want := []string{
+		"score: owner",
+		"jackpot: owner",
+		"score: preempted",
+		"score: owner",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("\n have: %v \n want: %v", log, want)
+	}
+	if s := g.Snapshot()[0]; s.Cond != "Preempted(display)" {
+		t.Errorf("\n have: %v \n want: %v", s.Cond, "Preempted(display)")
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestResourceSamePriority(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	flashers := NewResource("flashers")
+	var a, b *C
+	g.NewCoroutine(func(co *C) {
+		a = co
+		flashers.Acquire(co, 5)
+		co.WaitFor(testEvent("end"))
+	})
+	g.NewCoroutine(func(co *C) {
+		b = co
+		flashers.Acquire(co, 5)
+		co.WaitFor(testEvent("end"))
+	})
+	if !flashers.Owns(a) || flashers.Owns(b) {
+		t.Errorf("\n have: %v, %v \n want: %v, %v", flashers.Owns(a), flashers.Owns(b), true, false)
+	}
+	flashers.Release(a)
+	if !flashers.Owns(b) {
+		t.Errorf("\n have: %v \n want: %v", flashers.Owns(b), true)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestResourceReleaseOnCancel(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	speech := NewResource("speech")
+	cancel := g.NewCoroutine(func(co *C) {
+		co.New(func(co *C) {
+			speech.Acquire(co, 10)
+			co.WaitFor(testEvent("end"))
+		})
+		co.WaitFor(testEvent("end"))
+	})
+	owner := false
+	g.NewCoroutine(func(co *C) {
+		if done := speech.Acquire(co, 0); done {
+			return
+		}
+		owner = true
+		co.WaitFor(testEvent("end"))
+	})
+	g.Tick()
+	if owner {
+		t.Errorf("\n have: %v \n want: %v", owner, false)
+	}
+
+	// Canceling the parent releases the claim of the child
+	cancel()
+	if !owner {
+		t.Errorf("\n have: %v \n want: %v", owner, true)
+	}
+	g.Stop()
+	if speech.Owner() != nil {
+		t.Errorf("\n have: %v \n want: %v", speech.Owner(), nil)
+	}
+	wd.Stop()
+}
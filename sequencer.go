@@ -12,7 +12,7 @@ type Sequencer struct {
 }
 
 type opCancel struct {
-	fn func()
+	fn func(error)
 }
 
 type opDo struct {
@@ -54,6 +54,12 @@ func (s *Sequencer) checkClosed() {
 }
 
 func (s *Sequencer) Cancel(fn func()) {
+	s.ops = append(s.ops, opCancel{func(error) { fn() }})
+}
+
+// CancelCause is like Cancel but the function is given the reason why the
+// coroutine was canceled.
+func (s *Sequencer) CancelCause(fn func(cause error)) {
 	s.ops = append(s.ops, opCancel{fn})
 }
 
@@ -107,10 +113,10 @@ func (s *Sequencer) Run(co *C) bool {
 		}
 	}()
 
-	cancelFuncs := make([]func(), 0)
+	cancelFuncs := make([]func(error), 0)
 	cancel := func() {
 		for _, fn := range cancelFuncs {
-			fn()
+			fn(co.Cause())
 		}
 	}
 
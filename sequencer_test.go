@@ -1,6 +1,7 @@
 package coroutine
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -219,3 +220,33 @@ func TestDoRun(t *testing.T) {
 	cancel()
 	wd.Stop()
 }
+
+func TestSequencerCancelCause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	errTilt := errors.New("tilt")
+	var have error
+	callout := false
+
+	h := g.Spawn(func(co *C) {
+		s := NewSequencer()
+
+		s.CancelCause(func(cause error) {
+			have = cause
+			if cause != errTilt {
+				callout = true
+			}
+		})
+		s.WaitFor(testEvent("event"))
+		s.Run(co)
+	})
+
+	h.CancelWith(errTilt)
+	if have != errTilt {
+		t.Errorf("\n have: %v \n want: %v", have, errTilt)
+	}
+	if callout {
+		t.Errorf("not expecting callout on tilt")
+	}
+	wd.Stop()
+}
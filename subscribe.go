@@ -0,0 +1,119 @@
+package coroutine
+
+import "sort"
+
+// subscription is a handler owned by a coroutine that is called for every
+// event with one of the keys, or for every event accepted by the matcher if
+// set. Unless it buffers, it stops while the owner is paused. The sequence
+// number orders subscriptions by when they were made.
+type subscription struct {
+	owner    *C
+	seq      int
+	keys     []interface{}
+	match    Matcher
+	fn       func(Event)
+	buffers  bool
+	canceled bool
+}
+
+// matchAll is the key that subscriptions with a matcher are filed under
+// since they have to be checked for every event.
+type matchAll struct{}
+
+func (s *subscription) active() bool {
+	return !s.canceled && !s.owner.done && s.owner.cause == nil && (s.buffers || !s.owner.paused())
+}
+
+// subscriptions maps each event key to the subscriptions for it in the
+// order they were made.
+type subscriptions map[interface{}][]*subscription
+
+func (x subscriptions) add(sub *subscription) {
+	for _, key := range sub.keys {
+		x[key] = append(x[key], sub)
+	}
+}
+
+func (x subscriptions) remove(sub *subscription) {
+	for _, key := range sub.keys {
+		subs := x[key]
+		for i, s := range subs {
+			if s == sub {
+				subs = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(subs) == 0 {
+			delete(x, key)
+		} else {
+			x[key] = subs
+		}
+	}
+}
+
+func (x subscriptions) removeAll(co *C) {
+	for _, sub := range co.subs {
+		x.remove(sub)
+	}
+	co.subs = nil
+}
+
+// lookup returns the subscriptions for the event from highest to lowest
+// priority of their owners and then in the order they were made.
+func (x subscriptions) lookup(evt Event) []*subscription {
+	found := append([]*subscription(nil), x[evt.Key()]...)
+	for _, sub := range x[matchAll{}] {
+		if sub.match.Match(evt) {
+			found = append(found, sub)
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].owner.priority != found[j].owner.priority {
+			return found[i].owner.priority > found[j].owner.priority
+		}
+		return found[i].seq < found[j].seq
+	})
+	return found
+}
+
+// Subscribe calls the handler, from Tick, for every event that matches the
+// key of any of the given events. Unlike WaitFor, no events are missed
+// while the coroutine is busy. The handler must not yield but may call
+// Post or Consume. Handlers are called in priority order along with the
+// coroutines waiting for the event, ahead of those with the same priority.
+// The subscription is removed when the coroutine is canceled or exits, or
+// when the returned function is called.
+func (c *C) Subscribe(fn func(Event), events ...Event) CancelFunc {
+	return c.subscribe(&subscription{owner: c, keys: keysOf(events), fn: fn})
+}
+
+func (c *C) subscribe(sub *subscription) CancelFunc {
+	c.group.nextSub++
+	sub.seq = c.group.nextSub
+	c.group.subs.add(sub)
+	c.subs = append(c.subs, sub)
+	return func() {
+		if sub.canceled {
+			return
+		}
+		sub.canceled = true
+		c.group.subs.remove(sub)
+		for i, s := range c.subs {
+			if s == sub {
+				c.subs = append(c.subs[:i], c.subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func keysOf(events []Event) []interface{} {
+	keys := make([]interface{}, len(events))
+	for i, evt := range events {
+		keys[i] = evt.Key()
+	}
+	return keys
+}
@@ -0,0 +1,135 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	score := 0
+	h := g.Spawn(func(co *C) {
+		co.Subscribe(func(evt Event) {
+			score += 10
+		}, testEvent("left sling"), testEvent("right sling"))
+		co.WaitFor(testEvent("end"))
+	})
+
+	// Delivered even while the coroutine is sleeping
+	g.NewCoroutine(func(co *C) {
+		co.Sleep(1 * time.Second)
+	})
+	g.Post(testEvent("left sling"))
+	g.Post(testEvent("right sling"))
+	g.Post(testEvent("left sling"))
+	g.Tick()
+	if score != 30 {
+		t.Errorf("\n have: %v \n want: %v", score, 30)
+	}
+	if g.UnmatchedCount() != 0 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 0)
+	}
+
+	h.Cancel()
+	clk.Add(2 * time.Second)
+	g.Post(testEvent("left sling"))
+	g.Tick()
+	if score != 30 {
+		t.Errorf("\n have: %v \n want: %v", score, 30)
+	}
+	if len(g.subs) != 0 {
+		t.Errorf("\n have: %v \n want: %v", len(g.subs), 0)
+	}
+	wd.Stop()
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	score := 0
+	var unsubscribe CancelFunc
+	g.NewCoroutine(func(co *C) {
+		unsubscribe = co.Subscribe(func(evt Event) {
+			score += 10
+		}, testEvent("sling"))
+		co.WaitFor(testEvent("end"))
+	})
+	g.Post(testEvent("sling"))
+	g.Tick()
+	unsubscribe()
+	unsubscribe()
+	g.Post(testEvent("sling"))
+	g.Tick()
+	if score != 10 {
+		t.Errorf("\n have: %v \n want: %v", score, 10)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestSubscribePriority(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []string
+	g.NewCoroutine(func(co *C) {
+		co.Subscribe(func(evt Event) {
+			have = append(have, "base")
+		}, testEvent("ramp"))
+		co.WaitFor(testEvent("end"))
+	})
+	g.NewCoroutine(func(co *C) {
+		co.WaitFor(testEvent("ramp"))
+		have = append(have, "waiting")
+		co.WaitFor(testEvent("end"))
+	})
+	g.NewCoroutine(func(co *C) {
+		co.Subscribe(func(evt Event) {
+			have = append(have, "multiball")
+			co.Consume()
+		}, testEvent("ramp"))
+		co.WaitFor(testEvent("end"))
+	}, WithPriority(10))
+	g.Post(testEvent("ramp"))
+	g.Tick()
+
+	want := []string{"multiball"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestSubscribeOrder(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var have []string
+	g.NewCoroutine(func(co *C) {
+		co.WaitFor(testEvent("ramp"))
+		have = append(have, "waiting")
+		co.WaitFor(testEvent("end"))
+	})
+	g.NewCoroutine(func(co *C) {
+		co.Subscribe(func(evt Event) {
+			have = append(have, "low")
+		}, testEvent("ramp"))
+		co.WaitFor(testEvent("end"))
+	}, WithPriority(-1))
+	g.NewCoroutine(func(co *C) {
+		co.Subscribe(func(evt Event) {
+			have = append(have, "same")
+		}, testEvent("ramp"))
+		co.WaitFor(testEvent("end"))
+	})
+	g.Post(testEvent("ramp"))
+	g.Tick()
+
+	want := []string{"same", "waiting", "low"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
@@ -0,0 +1,223 @@
+package coroutine
+
+import (
+	"errors"
+	"time"
+)
+
+// Strategy determines which children a supervisor restarts when one of
+// them exits.
+type Strategy int
+
+const (
+	// OneForOne restarts only the child that exited.
+	OneForOne Strategy = iota
+	// OneForAll cancels and restarts all children.
+	OneForAll
+	// RestForOne cancels and restarts the child that exited and all
+	// children added after it.
+	RestForOne
+)
+
+// Restart determines when a supervised child is restarted.
+type Restart int
+
+const (
+	// Permanent children are always restarted.
+	Permanent Restart = iota
+	// Transient children are restarted only if they panic.
+	Transient
+	// Temporary children are never restarted.
+	Temporary
+)
+
+const (
+	DefaultMaxRestarts   = 3
+	DefaultRestartPeriod = 5 * time.Second
+)
+
+var (
+	// ErrSupervisorRestart is the cause given to children canceled so that
+	// they can be restarted along with a sibling.
+	ErrSupervisorRestart = errors.New("restarted by supervisor")
+
+	// ErrSupervisorGaveUp is the cause given to children canceled when the
+	// supervisor has restarted children too many times.
+	ErrSupervisorGaveUp = errors.New("supervisor gave up")
+)
+
+// ChildRestartedEvent is posted each time a supervisor restarts a child.
+// The result is how the previous instance of the child exited.
+type ChildRestartedEvent struct {
+	Supervisor string
+	Child      string
+	Result     Result
+}
+
+func (e ChildRestartedEvent) Key() interface{} {
+	return ChildRestartedEvent{Supervisor: e.Supervisor, Child: e.Child}
+}
+
+// SupervisorGaveUpEvent is posted when a supervisor has exceeded the
+// maximum number of restarts. The child and result are for the child that
+// exited last.
+type SupervisorGaveUpEvent struct {
+	Supervisor string
+	Child      string
+	Result     Result
+}
+
+func (e SupervisorGaveUpEvent) Key() interface{} {
+	return SupervisorGaveUpEvent{Supervisor: e.Supervisor}
+}
+
+type supervised struct {
+	name    string
+	restart Restart
+	fn      func(*C)
+}
+
+// Supervisor runs a set of children and restarts them when they exit. If
+// children are restarted more than the maximum number of times within the
+// restart period, the supervisor cancels all children and gives up.
+type Supervisor struct {
+	name        string
+	strategy    Strategy
+	maxRestarts int
+	period      time.Duration
+	children    []supervised
+}
+
+func NewSupervisor(name string, strategy Strategy) *Supervisor {
+	return &Supervisor{
+		name:        name,
+		strategy:    strategy,
+		maxRestarts: DefaultMaxRestarts,
+		period:      DefaultRestartPeriod,
+		children:    make([]supervised, 0),
+	}
+}
+
+// Add adds a child to be started, in order, when the supervisor is run.
+func (s *Supervisor) Add(name string, restart Restart, fn func(*C)) {
+	s.children = append(s.children, supervised{name: name, restart: restart, fn: fn})
+}
+
+// SetIntensity sets the maximum number of restarts allowed within the
+// period.
+func (s *Supervisor) SetIntensity(maxRestarts int, period time.Duration) {
+	s.maxRestarts = maxRestarts
+	s.period = period
+}
+
+// Run starts all children and supervises them until they have all exited
+// without needing to be restarted or until the supervisor gives up. Returns
+// true if the coroutine was canceled.
+func (s *Supervisor) Run(co *C) bool {
+	handles := make([]*Handle, len(s.children))
+	for i, child := range s.children {
+		handles[i] = co.New(child.fn, WithName(child.name))
+	}
+
+	var restarts []time.Time
+	for {
+		// Find the first child that has exited. Children that do not need
+		// to be restarted are forgotten.
+		exited := -1
+		running := make([]Cond, 0, len(handles))
+		for i, h := range handles {
+			if h == nil {
+				continue
+			}
+			if !h.Done() {
+				running = append(running, Done(h))
+				continue
+			}
+			if !s.shouldRestart(s.children[i].restart, h.Result()) {
+				handles[i] = nil
+				continue
+			}
+			if exited < 0 {
+				exited = i
+			}
+		}
+
+		if exited < 0 {
+			if len(running) == 0 {
+				return false
+			}
+			if _, done := co.Wait(AnyOf(running...)); done {
+				return true
+			}
+			continue
+		}
+
+		result := handles[exited].Result()
+		now := co.group.Now()
+		restarts = append(restarts, now)
+		for len(restarts) > 0 && !now.Before(restarts[0].Add(s.period)) {
+			restarts = restarts[1:]
+		}
+		if len(restarts) > s.maxRestarts {
+			for _, h := range handles {
+				if h != nil {
+					h.co.cancel(ErrSupervisorGaveUp)
+				}
+			}
+			co.Post(SupervisorGaveUpEvent{
+				Supervisor: s.name,
+				Child:      s.children[exited].name,
+				Result:     result,
+			})
+			return false
+		}
+
+		// Determine which children are restarted along with the one that
+		// exited and cancel them
+		restart := []int{exited}
+		switch s.strategy {
+		case OneForAll:
+			restart = restart[:0]
+			for i, h := range handles {
+				if h != nil {
+					restart = append(restart, i)
+				}
+			}
+		case RestForOne:
+			for i := exited + 1; i < len(handles); i++ {
+				if handles[i] != nil {
+					restart = append(restart, i)
+				}
+			}
+		}
+		stopping := make([]Cond, 0, len(restart))
+		for _, i := range restart {
+			if !handles[i].Done() {
+				handles[i].co.cancel(ErrSupervisorRestart)
+				stopping = append(stopping, Done(handles[i]))
+			}
+		}
+		if _, done := co.Wait(AllOf(stopping...)); done {
+			return true
+		}
+
+		for _, i := range restart {
+			co.Post(ChildRestartedEvent{
+				Supervisor: s.name,
+				Child:      s.children[i].name,
+				Result:     handles[i].Result(),
+			})
+			handles[i] = co.New(s.children[i].fn, WithName(s.children[i].name))
+		}
+	}
+}
+
+func (s *Supervisor) shouldRestart(restart Restart, result Result) bool {
+	switch restart {
+	case Permanent:
+		return true
+	case Transient:
+		return result.Reason == Panicked
+	}
+	return false
+}
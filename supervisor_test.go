@@ -0,0 +1,254 @@
+package coroutine
+
+import (
+	"testing"
+	"time"
+)
+
+// starts returns a child that counts how many times it has been started and
+// then panics when the event is received.
+func starts(n *int, evt testEvent) func(*C) {
+	return func(co *C) {
+		*n += 1
+		if _, done := co.WaitFor(evt); done {
+			return
+		}
+		panic(string(evt))
+	}
+}
+
+func TestOneForOne(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	g.SetPanicHandler(nil)
+	a := 0
+	b := 0
+	var restarted []Event
+
+	g.NewCoroutine(func(co *C) {
+		for {
+			evt, done := co.WaitFor(ChildRestartedEvent{Supervisor: "sup", Child: "a"})
+			if done {
+				return
+			}
+			restarted = append(restarted, evt)
+		}
+	})
+	cancel := g.NewCoroutine(func(co *C) {
+		s := NewSupervisor("sup", OneForOne)
+		s.Add("a", Permanent, starts(&a, "a fail"))
+		s.Add("b", Permanent, starts(&b, "b fail"))
+		s.Run(co)
+	})
+
+	g.Post(testEvent("a fail"))
+	g.Tick()
+	if a != 2 {
+		t.Errorf("\n have: %v \n want: %v", a, 2)
+	}
+	if b != 1 {
+		t.Errorf("\n have: %v \n want: %v", b, 1)
+	}
+	if len(restarted) != 1 {
+		t.Fatalf("\n have: %v \n want: %v", len(restarted), 1)
+	}
+	reason := restarted[0].(ChildRestartedEvent).Result.Reason
+	if reason != Panicked {
+		t.Errorf("\n have: %v \n want: %v", reason, Panicked)
+	}
+
+	cancel()
+	g.Stop()
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
+
+func TestOneForAll(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	g.SetPanicHandler(nil)
+	a := 0
+	b := 0
+	var bCause error
+
+	cancel := g.NewCoroutine(func(co *C) {
+		s := NewSupervisor("sup", OneForAll)
+		s.Add("a", Permanent, starts(&a, "a fail"))
+		s.Add("b", Permanent, func(co *C) {
+			b += 1
+			if _, done := co.WaitFor(testEvent("b fail")); done {
+				bCause = co.Cause()
+			}
+		})
+		s.Run(co)
+	})
+
+	g.Post(testEvent("a fail"))
+	g.Tick()
+	if a != 2 {
+		t.Errorf("\n have: %v \n want: %v", a, 2)
+	}
+	if b != 2 {
+		t.Errorf("\n have: %v \n want: %v", b, 2)
+	}
+	if bCause != ErrSupervisorRestart {
+		t.Errorf("\n have: %v \n want: %v", bCause, ErrSupervisorRestart)
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestRestForOne(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	g.SetPanicHandler(nil)
+	a := 0
+	b := 0
+	c := 0
+
+	cancel := g.NewCoroutine(func(co *C) {
+		s := NewSupervisor("sup", RestForOne)
+		s.Add("a", Permanent, starts(&a, "a fail"))
+		s.Add("b", Permanent, starts(&b, "b fail"))
+		s.Add("c", Permanent, starts(&c, "c fail"))
+		s.Run(co)
+	})
+
+	g.Post(testEvent("b fail"))
+	g.Tick()
+	have := []int{a, b, c}
+	want := []int{1, 2, 2}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestRestartType(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g := NewGroup()
+	g.SetPanicHandler(nil)
+	a := 0
+	b := 0
+	c := 0
+	supDone := false
+
+	cancel := g.NewCoroutine(func(co *C) {
+		s := NewSupervisor("sup", OneForOne)
+		s.Add("a", Transient, func(co *C) {
+			a += 1
+			evt, done := co.WaitFor(testEvent("a exit"), testEvent("a fail"))
+			if done {
+				return
+			}
+			if evt == testEvent("a fail") {
+				panic("fail")
+			}
+		})
+		s.Add("b", Temporary, starts(&b, "b fail"))
+		s.Add("c", Transient, starts(&c, "c fail"))
+		supDone = s.Run(co)
+		co.WaitFor(testEvent("end"))
+	})
+
+	g.Post(testEvent("a fail"))
+	g.Post(testEvent("b fail"))
+	g.Tick()
+	have := []int{a, b, c}
+	want := []int{2, 1, 1}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+
+	g.Post(testEvent("a exit"))
+	g.Post(testEvent("c fail"))
+	g.Tick()
+	have = []int{a, b, c}
+	want = []int{2, 1, 2}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("\n have: %v \n want: %v", have, want)
+		}
+	}
+	if supDone {
+		t.Errorf("not expecting supervisor to be canceled")
+	}
+	cancel()
+	wd.Stop()
+}
+
+func TestSupervisorGiveUp(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	g.SetPanicHandler(nil)
+	a := 0
+	b := 0
+	var bCause error
+	var gaveUp []Event
+
+	g.NewCoroutine(func(co *C) {
+		evt, done := co.WaitFor(SupervisorGaveUpEvent{Supervisor: "sup"})
+		if done {
+			return
+		}
+		gaveUp = append(gaveUp, evt)
+	})
+	h := g.Spawn(func(co *C) {
+		s := NewSupervisor("sup", OneForOne)
+		s.SetIntensity(2, 10*time.Second)
+		s.Add("a", Permanent, starts(&a, "a fail"))
+		s.Add("b", Permanent, func(co *C) {
+			b += 1
+			if _, done := co.WaitFor(testEvent("b fail")); done {
+				bCause = co.Cause()
+			}
+		})
+		s.Run(co)
+	})
+
+	// Restarts outside of the period are not counted
+	for i := 0; i < 3; i++ {
+		clk.Add(11 * time.Second)
+		g.Post(testEvent("a fail"))
+		g.Tick()
+	}
+	if a != 4 {
+		t.Errorf("\n have: %v \n want: %v", a, 4)
+	}
+	if len(gaveUp) != 0 {
+		t.Errorf("\n have: %v \n want: %v", len(gaveUp), 0)
+	}
+
+	g.Post(testEvent("a fail"))
+	g.Tick()
+	g.Post(testEvent("a fail"))
+	g.Tick()
+	if a != 5 {
+		t.Errorf("\n have: %v \n want: %v", a, 5)
+	}
+	if len(gaveUp) != 1 {
+		t.Fatalf("\n have: %v \n want: %v", len(gaveUp), 1)
+	}
+	if child := gaveUp[0].(SupervisorGaveUpEvent).Child; child != "a" {
+		t.Errorf("\n have: %v \n want: %v", child, "a")
+	}
+	if bCause != ErrSupervisorGaveUp {
+		t.Errorf("\n have: %v \n want: %v", bCause, ErrSupervisorGaveUp)
+	}
+	if !h.Done() {
+		t.Errorf("expecting supervisor to be done")
+	}
+	running := g.running()
+	if running != 0 {
+		t.Errorf("\n have: %v \n want: %v", running, 0)
+	}
+	wd.Stop()
+}
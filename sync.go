@@ -0,0 +1,266 @@
+package coroutine
+
+import "fmt"
+
+// Order determines which waiting coroutine is next to acquire a Mutex or
+// Semaphore.
+type Order int
+
+const (
+	// FIFO grants in the order that coroutines started waiting.
+	FIFO Order = iota
+	// ByPriority grants to the waiting coroutine with the highest priority
+	// and then in the order they started waiting.
+	ByPriority
+)
+
+type waiter struct {
+	co      *C
+	granted bool
+}
+
+type waitQueue struct {
+	order   Order
+	waiters []*waiter
+}
+
+func (q *waitQueue) push(co *C) *waiter {
+	w := &waiter{co: co}
+	q.waiters = append(q.waiters, w)
+	return w
+}
+
+// pop removes and returns the next waiter or nil if there are none.
+// Coroutines that have been canceled are passed over since they are about
+// to stop waiting.
+func (q *waitQueue) pop() *waiter {
+	next := -1
+	for i, w := range q.waiters {
+		if w.co.done || w.co.cause != nil {
+			continue
+		}
+		if next < 0 || (q.order == ByPriority && w.co.priority > q.waiters[next].co.priority) {
+			next = i
+		}
+		if q.order == FIFO {
+			break
+		}
+	}
+	if next < 0 {
+		return nil
+	}
+	w := q.waiters[next]
+	q.waiters = append(q.waiters[:next], q.waiters[next+1:]...)
+	return w
+}
+
+// waiting returns true if there are coroutines waiting that have not been
+// canceled.
+func (q *waitQueue) waiting() bool {
+	for _, w := range q.waiters {
+		if !w.co.done && w.co.cause == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *waitQueue) remove(w *waiter) {
+	for i, waiting := range q.waiters {
+		if waiting == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Semaphore limits the number of coroutines that can hold it at once.
+// Permits are handed directly to waiting coroutines when released so that
+// a coroutine cannot take a permit ahead of those already waiting.
+type Semaphore struct {
+	name      string
+	available int
+	queue     waitQueue
+}
+
+// NewSemaphore creates a semaphore with the given number of permits.
+func NewSemaphore(name string, permits int, order Order) *Semaphore {
+	return &Semaphore{
+		name:      name,
+		available: permits,
+		queue:     waitQueue{order: order},
+	}
+}
+
+// Acquire takes a permit or yields until one is available. Returns true if
+// the coroutine was canceled, in which case it does not hold a permit.
+func (s *Semaphore) Acquire(co *C) bool {
+	return s.acquire(co, "Acquire")
+}
+
+func (s *Semaphore) acquire(co *C, op string) bool {
+	if s.TryAcquire() {
+		return false
+	}
+	w := s.queue.push(co)
+	_, done := co.Wait(until(fmt.Sprintf("%v(%v)", op, s.name), func() bool {
+		return w.granted
+	}))
+	if done {
+		if w.granted {
+			s.Release()
+		} else {
+			s.queue.remove(w)
+		}
+	}
+	return done
+}
+
+// TryAcquire takes a permit if one is available without yielding. Returns
+// true if a permit was taken.
+func (s *Semaphore) TryAcquire() bool {
+	if s.available > 0 && !s.queue.waiting() {
+		s.available--
+		return true
+	}
+	return false
+}
+
+// Release returns a permit and gives it to the next waiting coroutine, if
+// any.
+func (s *Semaphore) Release() {
+	if w := s.queue.pop(); w != nil {
+		w.granted = true
+		return
+	}
+	s.available++
+}
+
+// Available returns the number of permits that can be acquired without
+// waiting.
+func (s *Semaphore) Available() int {
+	return s.available
+}
+
+// Mutex is held by at most one coroutine at a time. The mutex is not
+// released when the holder is canceled so it should be unlocked with
+// defer.
+type Mutex struct {
+	sem    *Semaphore
+	locked bool
+}
+
+// NewMutex creates an unlocked mutex.
+func NewMutex(name string, order Order) *Mutex {
+	return &Mutex{sem: NewSemaphore(name, 1, order)}
+}
+
+// Lock yields until the mutex is held. Returns true if the coroutine was
+// canceled, in which case it does not hold the mutex.
+func (m *Mutex) Lock(co *C) bool {
+	if done := m.sem.acquire(co, "Lock"); done {
+		return true
+	}
+	m.locked = true
+	return false
+}
+
+// TryLock locks the mutex if it is not held without yielding. Returns true
+// if the mutex was locked.
+func (m *Mutex) TryLock() bool {
+	if !m.sem.TryAcquire() {
+		return false
+	}
+	m.locked = true
+	return true
+}
+
+// Unlock releases the mutex to the next waiting coroutine, if any. Panics
+// if the mutex is not locked.
+func (m *Mutex) Unlock() {
+	if !m.locked {
+		panic("coroutine: unlock of unlocked mutex")
+	}
+	m.locked = false
+	m.sem.Release()
+}
+
+// Locked returns true if the mutex is held.
+func (m *Mutex) Locked() bool {
+	return m.locked
+}
+
+// Barrier lets a number of coroutines wait for each other. Once the last
+// one arrives, all are resumed and the barrier can be used again.
+type Barrier struct {
+	name    string
+	parties int
+	waiting int
+	round   int
+}
+
+// NewBarrier creates a barrier for the given number of coroutines.
+func NewBarrier(name string, parties int) *Barrier {
+	return &Barrier{name: name, parties: parties}
+}
+
+// Wait yields until the given number of coroutines are waiting on the
+// barrier. The last coroutine to arrive does not yield. Returns true if
+// the coroutine was canceled, in which case it no longer counts towards
+// the barrier.
+func (b *Barrier) Wait(co *C) bool {
+	b.waiting++
+	if b.waiting >= b.parties {
+		b.waiting = 0
+		b.round++
+		return false
+	}
+	round := b.round
+	_, done := co.Wait(until(fmt.Sprintf("Barrier(%v)", b.name), func() bool {
+		return b.round != round
+	}))
+	if done && b.round == round {
+		b.waiting--
+	}
+	return done
+}
+
+// Waiting returns the number of coroutines waiting on the barrier.
+func (b *Barrier) Waiting() int {
+	return b.waiting
+}
+
+// CountDownLatch lets coroutines wait until a count reaches zero. Once
+// zero, the latch stays open.
+type CountDownLatch struct {
+	name  string
+	count int
+}
+
+// NewCountDownLatch creates a latch that opens after CountDown has been
+// called count times.
+func NewCountDownLatch(name string, count int) *CountDownLatch {
+	return &CountDownLatch{name: name, count: count}
+}
+
+// CountDown decrements the count. Coroutines waiting are resumed once it
+// reaches zero.
+func (l *CountDownLatch) CountDown() {
+	if l.count > 0 {
+		l.count--
+	}
+}
+
+// Count returns the current count.
+func (l *CountDownLatch) Count() int {
+	return l.count
+}
+
+// Wait yields until the count reaches zero. Returns true if the coroutine
+// was canceled.
+func (l *CountDownLatch) Wait(co *C) bool {
+	_, done := co.Wait(until(fmt.Sprintf("Latch(%v)", l.name), func() bool {
+		return l.count == 0
+	}))
+	return done
+}
@@ -0,0 +1,216 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMutexFIFO(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	m := NewMutex("speech", FIFO)
+	var have []string
+	speak := func(name string) func(*C) {
+		return func(co *C) {
+			if done := m.Lock(co); done {
+				return
+			}
+			defer m.Unlock()
+			have = append(have, name)
+			co.Sleep(1 * time.Second)
+		}
+	}
+	g.NewCoroutine(speak("jackpot"))
+	g.NewCoroutine(speak("extra ball"), WithPriority(10))
+	g.NewCoroutine(speak("replay"))
+	if !m.Locked() {
+		t.Errorf("\n have: %v \n want: %v", m.Locked(), true)
+	}
+	if s := g.Snapshot()[1]; s.Cond != "Lock(speech)" {
+		t.Errorf("\n have: %v \n want: %v", s.Cond, "Lock(speech)")
+	}
+	for i := 0; i < 3; i++ {
+		clk.Add(1100 * time.Millisecond)
+		g.Tick()
+	}
+	want := []string{"jackpot", "extra ball", "replay"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	if m.Locked() {
+		t.Errorf("\n have: %v \n want: %v", m.Locked(), false)
+	}
+	wd.Stop()
+}
+
+func TestSemaphoreByPriority(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	s := NewSemaphore("kicker", 2, ByPriority)
+	var have []string
+	kick := func(name string) func(*C) {
+		return func(co *C) {
+			if done := s.Acquire(co); done {
+				return
+			}
+			defer s.Release()
+			have = append(have, name)
+			co.Sleep(1 * time.Second)
+		}
+	}
+	g.NewCoroutine(kick("a"))
+	g.NewCoroutine(kick("b"))
+	g.NewCoroutine(kick("c"))
+	g.NewCoroutine(kick("d"), WithPriority(5))
+	g.NewCoroutine(kick("e"), WithPriority(1))
+	if s.Available() != 0 {
+		t.Errorf("\n have: %v \n want: %v", s.Available(), 0)
+	}
+	for i := 0; i < 3; i++ {
+		clk.Add(1100 * time.Millisecond)
+		g.Tick()
+	}
+	want := []string{"a", "b", "d", "e", "c"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	if s.Available() != 2 {
+		t.Errorf("\n have: %v \n want: %v", s.Available(), 2)
+	}
+	wd.Stop()
+}
+
+func TestMutexCanceled(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	m := NewMutex("magnet", FIFO)
+	if !m.TryLock() {
+		t.Fatalf("expecting lock")
+	}
+	canceled := false
+	cancel := g.NewCoroutine(func(co *C) {
+		canceled = m.Lock(co)
+	})
+	locked := false
+	g.NewCoroutine(func(co *C) {
+		if done := m.Lock(co); !done {
+			locked = true
+		}
+	})
+	cancel()
+	if !canceled {
+		t.Errorf("\n have: %v \n want: %v", canceled, true)
+	}
+	m.Unlock()
+	g.Tick()
+	if !locked {
+		t.Errorf("\n have: %v \n want: %v", locked, true)
+	}
+	if m.TryLock() {
+		t.Errorf("expecting mutex to be held")
+	}
+	wd.Stop()
+}
+
+func TestSemaphoreCanceledAfterRelease(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	s := NewSemaphore("trough", 1, FIFO)
+	s.TryAcquire()
+	h := g.Spawn(func(co *C) {
+		s.Acquire(co)
+	})
+
+	// The permit is not given to a coroutine that is being canceled
+	h.co.cancel(ErrCanceled)
+	s.Release()
+	g.Tick()
+	if s.Available() != 1 {
+		t.Errorf("\n have: %v \n want: %v", s.Available(), 1)
+	}
+	if !s.TryAcquire() {
+		t.Errorf("expecting permit")
+	}
+	wd.Stop()
+}
+
+func TestBarrier(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	b := NewBarrier("multiball", 3)
+	var have []string
+	ball := func(name string, d time.Duration) func(*C) {
+		return func(co *C) {
+			for round := 0; round < 2; round++ {
+				co.Sleep(d)
+				if done := b.Wait(co); done {
+					return
+				}
+				have = append(have, name)
+			}
+		}
+	}
+	g.NewCoroutine(ball("a", 1*time.Second))
+	g.NewCoroutine(ball("b", 2*time.Second))
+	g.NewCoroutine(ball("c", 3*time.Second))
+	for i := 0; i < 2; i++ {
+		clk.Add(1100 * time.Millisecond)
+		g.Tick()
+		if b.Waiting() != i+1 {
+			t.Errorf("\n have: %v \n want: %v", b.Waiting(), i+1)
+		}
+	}
+	if len(have) != 0 {
+		t.Fatalf("\n have: %v \n want: %v", have, nil)
+	}
+	clk.Add(1 * time.Second)
+	g.Tick()
+	want := []string{"c", "a", "b"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %v \n want: %v", have, want)
+	}
+	clk.Add(3100 * time.Millisecond)
+	g.Tick()
+	if len(have) != 6 {
+		t.Errorf("\n have: %v \n want: %v", len(have), 6)
+	}
+	wd.Stop()
+}
+
+func TestCountDownLatch(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	l := NewCountDownLatch("targets", 3)
+	done := false
+	g.NewCoroutine(func(co *C) {
+		l.Wait(co)
+		done = true
+	})
+	for i := 0; i < 3; i++ {
+		g.NewCoroutine(func(co *C) {
+			co.Sleep(1 * time.Second)
+			l.CountDown()
+		})
+	}
+	g.Tick()
+	if done || l.Count() != 3 {
+		t.Errorf("\n have: %v, %v \n want: %v, %v", done, l.Count(), false, 3)
+	}
+	clk.Add(2 * time.Second)
+	g.Tick()
+	if !done || l.Count() != 0 {
+		t.Errorf("\n have: %v, %v \n want: %v, %v", done, l.Count(), true, 0)
+	}
+
+	// Stays open
+	waited := false
+	g.NewCoroutine(func(co *C) {
+		l.Wait(co)
+		waited = true
+	})
+	if !waited {
+		t.Errorf("\n have: %v \n want: %v", waited, true)
+	}
+	wd.Stop()
+}
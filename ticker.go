@@ -0,0 +1,112 @@
+package coroutine
+
+import (
+	"fmt"
+	"time"
+)
+
+// CatchUp determines what a ticker does when one or more periods have
+// been missed because the owner was busy or ticks were late.
+type CatchUp int
+
+const (
+	// CatchUpSkip drops the missed periods and waits for the next
+	// deadline that is still in the future.
+	CatchUpSkip CatchUp = iota
+	// CatchUpBurst returns from Wait without yielding once for each
+	// missed period until the ticker has caught up.
+	CatchUpBurst
+	// CatchUpReset starts a new schedule one period from now.
+	CatchUpReset
+)
+
+// Ticker resumes its owner once per period. Each deadline is computed from
+// the previous deadline, not from the time Wait was called, so the ticker
+// does not drift. Time spent paused while waiting moves the schedule later
+// and does not count as missed periods.
+type Ticker struct {
+	co      *C
+	d       time.Duration
+	next    time.Time
+	catchUp CatchUp
+	missed  int
+	stopped bool
+}
+
+// NewTicker creates a ticker owned by the coroutine with the first deadline
+// one period from now. Missed periods are skipped unless changed with
+// SetCatchUp.
+func (c *C) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("coroutine: non-positive ticker period")
+	}
+	return &Ticker{co: c, d: d, next: c.group.Now().Add(d)}
+}
+
+// SetCatchUp sets what happens when periods are missed.
+func (t *Ticker) SetCatchUp(c CatchUp) {
+	t.catchUp = c
+}
+
+// Missed returns the number of periods that have been skipped.
+func (t *Ticker) Missed() int {
+	return t.missed
+}
+
+// Stop stops the ticker. A coroutine waiting on the ticker is resumed and
+// Wait returns true from then on.
+func (t *Ticker) Stop() {
+	t.stopped = true
+}
+
+// Wait yields until the next deadline. Returns true if the owner was
+// canceled or the ticker was stopped. Must only be called from the owner.
+func (t *Ticker) Wait() bool {
+	if t.stopped || t.co.cause != nil {
+		return true
+	}
+	now := t.co.group.Now()
+	if !now.After(t.next) {
+		stopped := until(fmt.Sprintf("Stopped(%v)", t.d), func() bool {
+			return t.stopped
+		})
+		w := newWait(AnyOf(Timeout(t.next.Sub(now)), stopped), now)
+		_, done := t.co.waitOn(w)
+		if done || t.stopped {
+			return true
+		}
+		// The timeout is moved later by the time spent paused so the
+		// schedule continues from there.
+		t.next = w.children[0].expires
+		now = t.co.group.Now()
+	}
+
+	t.next = t.next.Add(t.d)
+	if now.After(t.next) {
+		switch t.catchUp {
+		case CatchUpSkip:
+			n := now.Sub(t.next)/t.d + 1
+			t.next = t.next.Add(n * t.d)
+			t.missed += int(n)
+		case CatchUpReset:
+			t.next = now.Add(t.d)
+		}
+	}
+	return false
+}
+
+// Every calls the function once per period in a child coroutine until
+// this coroutine is canceled or the returned handle is canceled. The
+// function is given the child coroutine and may yield, in which case the
+// periods missed while it runs are skipped.
+func (c *C) Every(d time.Duration, fn func(*C), opts ...Option) *Handle {
+	return c.New(func(co *C) {
+		t := co.NewTicker(d)
+		for {
+			if done := t.Wait(); done {
+				return
+			}
+			fn(co)
+		}
+	}, opts...)
+}
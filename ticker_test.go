@@ -0,0 +1,175 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTickerNoDrift(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	start := clk.Now()
+	var fired []time.Duration
+	g.NewCoroutine(func(co *C) {
+		t := co.NewTicker(100 * time.Millisecond)
+		for {
+			if done := t.Wait(); done {
+				return
+			}
+			fired = append(fired, g.Now().Sub(start))
+		}
+	})
+	for i := 0; i < 32; i++ {
+		clk.Add(16 * time.Millisecond)
+		g.Tick()
+	}
+
+	// Resumed on the first frame after each deadline
+	want := []time.Duration{
+		112 * time.Millisecond,
+		208 * time.Millisecond,
+		304 * time.Millisecond,
+		416 * time.Millisecond,
+		512 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(fired, want) {
+		t.Errorf("\n have: %v \n want: %v", fired, want)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestTickerCatchUp(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	tests := []struct {
+		catchUp CatchUp
+		fired   int
+		missed  int
+		next    time.Duration
+	}{
+		{CatchUpSkip, 1, 2, 400 * time.Millisecond},
+		{CatchUpBurst, 3, 0, 400 * time.Millisecond},
+		{CatchUpReset, 1, 0, 450 * time.Millisecond},
+	}
+	for _, test := range tests {
+		g, clk := newMockGroup()
+		start := clk.Now()
+		fired := 0
+		var ticker *Ticker
+		g.NewCoroutine(func(co *C) {
+			ticker = co.NewTicker(100 * time.Millisecond)
+			ticker.SetCatchUp(test.catchUp)
+			for {
+				if done := ticker.Wait(); done {
+					return
+				}
+				fired++
+			}
+		})
+		clk.Add(350 * time.Millisecond)
+		g.Tick()
+		if fired != test.fired {
+			t.Errorf("%v\n have: %v \n want: %v", test.catchUp, fired, test.fired)
+		}
+		if ticker.Missed() != test.missed {
+			t.Errorf("%v\n have: %v \n want: %v", test.catchUp, ticker.Missed(), test.missed)
+		}
+		if next := ticker.next.Sub(start); next != test.next {
+			t.Errorf("%v\n have: %v \n want: %v", test.catchUp, next, test.next)
+		}
+		g.Stop()
+	}
+	wd.Stop()
+}
+
+func TestTickerPause(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	for _, catchUp := range []CatchUp{CatchUpSkip, CatchUpBurst} {
+		g, clk := newMockGroup()
+		start := clk.Now()
+		var fired []time.Duration
+		var ticker *Ticker
+		h := g.Spawn(func(co *C) {
+			ticker = co.NewTicker(100 * time.Millisecond)
+			ticker.SetCatchUp(catchUp)
+			for {
+				if done := ticker.Wait(); done {
+					return
+				}
+				fired = append(fired, g.Now().Sub(start))
+			}
+		})
+		clk.Add(50 * time.Millisecond)
+		g.Tick()
+		h.Pause()
+		clk.Add(300 * time.Millisecond)
+		g.Tick()
+		h.Resume()
+
+		// The deadline at 100ms has moved to 400ms
+		for i := 0; i < 2; i++ {
+			clk.Add(60 * time.Millisecond)
+			g.Tick()
+			clk.Add(40 * time.Millisecond)
+			g.Tick()
+		}
+		want := []time.Duration{
+			410 * time.Millisecond,
+			510 * time.Millisecond,
+		}
+		if !reflect.DeepEqual(fired, want) {
+			t.Errorf("%v\n have: %v \n want: %v", catchUp, fired, want)
+		}
+		if ticker.Missed() != 0 {
+			t.Errorf("%v\n have: %v \n want: %v", catchUp, ticker.Missed(), 0)
+		}
+		g.Stop()
+	}
+	wd.Stop()
+}
+
+func TestTickerStop(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var ticker *Ticker
+	stopped := false
+	g.NewCoroutine(func(co *C) {
+		ticker = co.NewTicker(1 * time.Second)
+		stopped = ticker.Wait()
+		co.WaitFor(testEvent("end"))
+	})
+	ticker.Stop()
+	g.Tick()
+	if !stopped {
+		t.Errorf("\n have: %v \n want: %v", stopped, true)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestEvery(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	blinks := 0
+	cancel := g.NewCoroutine(func(co *C) {
+		co.Every(250*time.Millisecond, func(co *C) {
+			blinks++
+		}, WithName("blinker"))
+		co.WaitFor(testEvent("end"))
+	})
+	for i := 0; i < 10; i++ {
+		clk.Add(101 * time.Millisecond)
+		g.Tick()
+	}
+	if blinks != 4 {
+		t.Errorf("\n have: %v \n want: %v", blinks, 4)
+	}
+
+	// Canceled along with the owner
+	cancel()
+	if g.running() != 0 {
+		t.Errorf("\n have: %v \n want: %v", g.running(), 0)
+	}
+	wd.Stop()
+}
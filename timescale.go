@@ -0,0 +1,55 @@
+package coroutine
+
+import (
+	"time"
+)
+
+// timeScale converts time from the clock of the group into game time that
+// can run faster or slower. Game time starts out equal to clock time.
+type timeScale struct {
+	scale float64
+	// Game time and clock time when the scale was last changed.
+	base      time.Time
+	clockBase time.Time
+}
+
+func newTimeScale(now time.Time) timeScale {
+	return timeScale{scale: 1, base: now, clockBase: now}
+}
+
+func (t timeScale) at(clockNow time.Time) time.Time {
+	if t.scale == 1 {
+		return t.base.Add(clockNow.Sub(t.clockBase))
+	}
+	elapsed := float64(clockNow.Sub(t.clockBase)) * t.scale
+	return t.base.Add(time.Duration(elapsed))
+}
+
+// Now returns the current game time. All sleeps and timeouts are measured
+// in game time.
+func (g *Group) Now() time.Time {
+	return g.timeScale.at(g.clock.Now())
+}
+
+// SetTimeScale changes how fast game time passes compared to the clock of
+// the group. A scale of 2 runs twice as fast and a scale of 0.5 runs at
+// half speed. A scale of 0 stops game time. Sleeps and timeouts already in
+// progress keep the same game time deadlines so the time remaining on them
+// passes at the new rate.
+func (g *Group) SetTimeScale(scale float64) {
+	if scale < 0 {
+		panic("time scale must not be negative")
+	}
+	clockNow := g.clock.Now()
+	g.timeScale = timeScale{
+		scale:     scale,
+		base:      g.timeScale.at(clockNow),
+		clockBase: clockNow,
+	}
+}
+
+// TimeScale returns how fast game time passes compared to the clock of the
+// group.
+func (g *Group) TimeScale() float64 {
+	return g.timeScale.scale
+}
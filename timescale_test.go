@@ -0,0 +1,94 @@
+package coroutine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeScale(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	g.SetTimeScale(4)
+	a := 0
+	g.NewCoroutine(func(co *C) {
+		if done := co.Sleep(4 * time.Second); done {
+			return
+		}
+		a = 1
+	})
+
+	clk.Add(900 * time.Millisecond)
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	clk.Add(200 * time.Millisecond)
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+
+	g.SetTimeScale(0.25)
+	g.NewCoroutine(func(co *C) {
+		if _, done := co.WaitForUntil(1*time.Second, testEvent("event")); done {
+			return
+		}
+		a = 2
+	})
+	clk.Add(3900 * time.Millisecond)
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	clk.Add(200 * time.Millisecond)
+	g.Tick()
+	if a != 2 {
+		t.Errorf("\n have: %v \n want: %v", a, 2)
+	}
+	wd.Stop()
+}
+
+func TestTimeScaleChange(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	a := 0
+	g.NewCoroutine(func(co *C) {
+		if done := co.Sleep(10 * time.Second); done {
+			return
+		}
+		a = 1
+	})
+
+	// 4 seconds of game time pass at normal speed, 6 remain
+	clk.Add(4 * time.Second)
+	g.Tick()
+	start := g.Now()
+
+	// Stopping game time stops the sleep
+	g.SetTimeScale(0)
+	clk.Add(1 * time.Minute)
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	if !g.Now().Equal(start) {
+		t.Errorf("\n have: %v \n want: %v", g.Now(), start)
+	}
+
+	// The remaining 6 seconds take 3 seconds at double speed
+	g.SetTimeScale(2)
+	clk.Add(2900 * time.Millisecond)
+	g.Tick()
+	if a != 0 {
+		t.Errorf("\n have: %v \n want: %v", a, 0)
+	}
+	clk.Add(200 * time.Millisecond)
+	g.Tick()
+	if a != 1 {
+		t.Errorf("\n have: %v \n want: %v", a, 1)
+	}
+	if g.TimeScale() != 2 {
+		t.Errorf("\n have: %v \n want: %v", g.TimeScale(), 2)
+	}
+	wd.Stop()
+}
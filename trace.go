@@ -0,0 +1,263 @@
+package coroutine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Tracer is notified of each action taken by the scheduler of a group. The
+// time given is from the clock of the group. Callbacks are made one at a
+// time, from the goroutine calling Tick or Spawn or from the coroutine
+// creating a child.
+type Tracer interface {
+	// Spawn is called when a coroutine is created, just before it runs to
+	// its first yield.
+	Spawn(t time.Time, h *Handle)
+	// ResumeEvent is called when a coroutine is resumed by an event.
+	ResumeEvent(t time.Time, h *Handle, evt Event)
+	// ResumeTimeout is called when a coroutine is resumed because a
+	// timeout has expired.
+	ResumeTimeout(t time.Time, h *Handle)
+	// ResumeDone is called when a coroutine is resumed because the
+	// coroutines it was joining have exited.
+	ResumeDone(t time.Time, h *Handle)
+	// Cancel is called when a canceled coroutine is resumed so that it can
+	// clean up.
+	Cancel(t time.Time, h *Handle, cause error)
+	// Yield is called when a coroutine yields back to the scheduler.
+	Yield(t time.Time, h *Handle)
+	// Exit is called when a coroutine has finished.
+	Exit(t time.Time, h *Handle, result Result)
+	// Unmatched is called when an event is delivered that no coroutine was
+	// waiting for.
+	Unmatched(t time.Time, evt Event)
+}
+
+// SetTracer sets the tracer that is notified of scheduler activity. Set to
+// nil to stop tracing.
+func (g *Group) SetTracer(t Tracer) {
+	g.tracer = t
+}
+
+func (g *Group) traceSpawn(co *C) {
+	if g.tracer != nil {
+		g.tracer.Spawn(g.clock.Now(), co.handle)
+	}
+}
+
+func (g *Group) traceResume(co *C, r response) {
+	if g.tracer == nil {
+		return
+	}
+	now := g.clock.Now()
+	switch {
+	case r.cause != nil:
+		g.tracer.Cancel(now, co.handle, r.cause)
+	case r.timeout:
+		g.tracer.ResumeTimeout(now, co.handle)
+	case r.event != nil:
+		g.tracer.ResumeEvent(now, co.handle, r.event)
+	default:
+		g.tracer.ResumeDone(now, co.handle)
+	}
+}
+
+func (g *Group) traceYield(co *C) {
+	if g.tracer != nil {
+		g.tracer.Yield(g.clock.Now(), co.handle)
+	}
+}
+
+func (g *Group) traceExit(co *C) {
+	if g.tracer != nil {
+		g.tracer.Exit(g.clock.Now(), co.handle, co.result)
+	}
+}
+
+func (g *Group) traceUnmatched(evt Event) {
+	if g.tracer != nil {
+		g.tracer.Unmatched(g.clock.Now(), evt)
+	}
+}
+
+// Logger is the structured logging method used by LogTracer. The arguments
+// are alternating keys and values. It is satisfied by *slog.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// LogTracer logs scheduler activity at the debug level with a structured
+// logger.
+type LogTracer struct {
+	log Logger
+}
+
+func NewLogTracer(log Logger) *LogTracer {
+	return &LogTracer{log: log}
+}
+
+func (l *LogTracer) Spawn(t time.Time, h *Handle) {
+	l.log.Debug("spawn", "id", h.ID(), "name", h.Name())
+}
+
+func (l *LogTracer) ResumeEvent(t time.Time, h *Handle, evt Event) {
+	l.log.Debug("resume", "id", h.ID(), "name", h.Name(), "reason", "event", "event", evt.Key())
+}
+
+func (l *LogTracer) ResumeTimeout(t time.Time, h *Handle) {
+	l.log.Debug("resume", "id", h.ID(), "name", h.Name(), "reason", "timeout")
+}
+
+func (l *LogTracer) ResumeDone(t time.Time, h *Handle) {
+	l.log.Debug("resume", "id", h.ID(), "name", h.Name(), "reason", "done")
+}
+
+func (l *LogTracer) Cancel(t time.Time, h *Handle, cause error) {
+	l.log.Debug("cancel", "id", h.ID(), "name", h.Name(), "cause", cause)
+}
+
+func (l *LogTracer) Yield(t time.Time, h *Handle) {
+	l.log.Debug("yield", "id", h.ID(), "name", h.Name())
+}
+
+func (l *LogTracer) Exit(t time.Time, h *Handle, result Result) {
+	args := []interface{}{"id", h.ID(), "name", h.Name(), "reason", result.Reason.String()}
+	if result.Err != nil {
+		args = append(args, "err", result.Err)
+	}
+	l.log.Debug("exit", args...)
+}
+
+func (l *LogTracer) Unmatched(t time.Time, evt Event) {
+	l.log.Debug("unmatched", "event", evt.Key())
+}
+
+// chromeEvent is a single entry in the Chrome trace event format.
+type chromeEvent struct {
+	Name  string                 `json:"name"`
+	Phase string                 `json:"ph"`
+	Time  float64                `json:"ts"`
+	Pid   int                    `json:"pid"`
+	Tid   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// ChromeTracer writes scheduler activity in the Chrome trace event format
+// which can be opened in chrome://tracing or Perfetto. Each coroutine is
+// shown as a thread and each time it runs is shown as a slice. Call Close
+// once tracing is done to finish the file.
+type ChromeTracer struct {
+	w       io.Writer
+	start   time.Time
+	started bool
+	running map[int]bool
+	err     error
+}
+
+func NewChromeTracer(w io.Writer) *ChromeTracer {
+	return &ChromeTracer{w: w, running: make(map[int]bool)}
+}
+
+// Err returns the first error encountered while writing.
+func (c *ChromeTracer) Err() error {
+	return c.err
+}
+
+// Close ends the list of trace events.
+func (c *ChromeTracer) Close() error {
+	if !c.started {
+		c.writeString("[")
+	}
+	c.writeString("\n]\n")
+	return c.err
+}
+
+func (c *ChromeTracer) writeString(s string) {
+	if c.err == nil {
+		_, c.err = io.WriteString(c.w, s)
+	}
+}
+
+func (c *ChromeTracer) write(t time.Time, e chromeEvent) {
+	if !c.started {
+		c.started = true
+		c.start = t
+		c.writeString("[\n")
+	} else {
+		c.writeString(",\n")
+	}
+	e.Pid = 1
+	e.Time = float64(t.Sub(c.start)) / float64(time.Microsecond)
+	data, err := json.Marshal(e)
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	c.writeString(string(data))
+}
+
+func (c *ChromeTracer) begin(t time.Time, h *Handle, name string, args map[string]interface{}) {
+	c.running[h.ID()] = true
+	c.write(t, chromeEvent{Name: name, Phase: "B", Tid: h.ID(), Args: args})
+}
+
+func (c *ChromeTracer) end(t time.Time, h *Handle, args map[string]interface{}) {
+	delete(c.running, h.ID())
+	c.write(t, chromeEvent{Phase: "E", Tid: h.ID(), Args: args})
+}
+
+func (c *ChromeTracer) Spawn(t time.Time, h *Handle) {
+	c.write(t, chromeEvent{
+		Name:  "thread_name",
+		Phase: "M",
+		Tid:   h.ID(),
+		Args:  map[string]interface{}{"name": fmt.Sprintf("%v (%v)", h.co.displayName(), h.ID())},
+	})
+	c.begin(t, h, "spawn", nil)
+}
+
+func (c *ChromeTracer) ResumeEvent(t time.Time, h *Handle, evt Event) {
+	c.begin(t, h, "event", map[string]interface{}{"event": fmt.Sprint(evt.Key())})
+}
+
+func (c *ChromeTracer) ResumeTimeout(t time.Time, h *Handle) {
+	c.begin(t, h, "timeout", nil)
+}
+
+func (c *ChromeTracer) ResumeDone(t time.Time, h *Handle) {
+	c.begin(t, h, "done", nil)
+}
+
+func (c *ChromeTracer) Cancel(t time.Time, h *Handle, cause error) {
+	c.begin(t, h, "cancel", map[string]interface{}{"cause": cause.Error()})
+}
+
+func (c *ChromeTracer) Yield(t time.Time, h *Handle) {
+	c.end(t, h, nil)
+}
+
+// Exit ends the slice of the coroutine if it is running. A coroutine that
+// yields after being canceled has already ended its slice so the exit is
+// shown as an instant instead.
+func (c *ChromeTracer) Exit(t time.Time, h *Handle, result Result) {
+	args := map[string]interface{}{"reason": result.Reason.String()}
+	if result.Err != nil {
+		args["err"] = result.Err.Error()
+	}
+	if c.running[h.ID()] {
+		c.end(t, h, args)
+		return
+	}
+	c.write(t, chromeEvent{Name: "exit", Phase: "i", Tid: h.ID(), Scope: "t", Args: args})
+}
+
+func (c *ChromeTracer) Unmatched(t time.Time, evt Event) {
+	c.write(t, chromeEvent{
+		Name:  "unmatched",
+		Phase: "i",
+		Scope: "p",
+		Args:  map[string]interface{}{"event": fmt.Sprint(evt.Key())},
+	})
+}
@@ -0,0 +1,145 @@
+package coroutine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lines is a Logger that records each message with its arguments
+type lines []string
+
+func (l *lines) Debug(msg string, args ...interface{}) {
+	*l = append(*l, strings.TrimSuffix(fmt.Sprintln(append([]interface{}{msg}, args...)...), "\n"))
+}
+
+func TestTracer(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	var log lines
+	g.SetTracer(NewLogTracer(&log))
+
+	var child *Handle
+	h := g.Spawn(func(co *C) {
+		child = co.New(func(co *C) {
+			co.Sleep(1 * time.Second)
+		}, WithName("timer"))
+		co.WaitFor(testEvent("ramp"))
+		co.Join(child)
+		co.WaitFor(testEvent("end"))
+	}, WithName("mode"))
+	g.Post(testEvent("ramp"))
+	g.Post(testEvent("orbit"))
+	g.Tick()
+	clk.Add(2 * time.Second)
+	g.Tick()
+	h.Cancel()
+
+	want := lines{
+		"spawn id 1 name mode",
+		"spawn id 2 name timer",
+		"yield id 2 name timer",
+		"yield id 1 name mode",
+		"resume id 1 name mode reason event event ramp",
+		"yield id 1 name mode",
+		"unmatched event orbit",
+		"resume id 2 name timer reason timeout",
+		"exit id 2 name timer reason exited",
+		"resume id 1 name mode reason done",
+		"yield id 1 name mode",
+		"cancel id 1 name mode cause coroutine canceled",
+		"exit id 1 name mode reason canceled err coroutine canceled",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("\n have: %q \n want: %q", log, want)
+	}
+	wd.Stop()
+}
+
+func TestTracerPartialMatch(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var log lines
+	g.NewCoroutine(func(co *C) {
+		co.Wait(AllOf(On(testEvent("ramp")), On(testEvent("orbit"))))
+	})
+	g.SetTracer(NewLogTracer(&log))
+	g.Post(testEvent("ramp"))
+	g.Tick()
+
+	// The event was accepted even though the coroutine was not resumed
+	if len(log) != 0 {
+		t.Errorf("\n have: %q \n want: %q", log, lines{})
+	}
+	wd.Stop()
+}
+
+func TestChromeTracer(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	var buf bytes.Buffer
+	tracer := NewChromeTracer(&buf)
+	g.SetTracer(tracer)
+
+	h := g.Spawn(func(co *C) {
+		co.Sleep(1 * time.Second)
+		co.SetResult(42)
+		co.WaitFor(testEvent("end"))
+	}, WithName("timer"))
+	g.Post(testEvent("unused"))
+	clk.Add(2 * time.Second)
+	g.Tick()
+	h.CancelWith(errors.New("tilt"))
+	if err := tracer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []chromeEvent
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatalf("%v: %s", err, buf.Bytes())
+	}
+	var have []string
+	for _, e := range events {
+		have = append(have, fmt.Sprintf("%v %v %v %v", e.Time, e.Phase, e.Tid, e.Name))
+	}
+	want := []string{
+		"0 M 1 thread_name",
+		"0 B 1 spawn",
+		"0 E 1 ",
+		"2e+06 B 1 timeout",
+		"2e+06 E 1 ",
+		"2e+06 i 0 unmatched",
+		"2e+06 B 1 cancel",
+		"2e+06 E 1 ",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\n have: %q \n want: %q", have, want)
+	}
+	if name := events[0].Args["name"]; name != "timer (1)" {
+		t.Errorf("\n have: %v \n want: %v", name, "timer (1)")
+	}
+	if err := events[len(events)-1].Args["err"]; err != "tilt" {
+		t.Errorf("\n have: %v \n want: %v", err, "tilt")
+	}
+	wd.Stop()
+}
+
+func TestChromeTracerEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewChromeTracer(&buf)
+	if err := tracer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var events []chromeEvent
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("\n have: %v \n want: %v", len(events), 0)
+	}
+}
@@ -0,0 +1,96 @@
+package coroutine
+
+import "time"
+
+// unmatch tracks events that were delivered when no coroutine was waiting
+// for them.
+type unmatch struct {
+	handler  func(Event)
+	count    int
+	ticks    int
+	d        time.Duration
+	retained []retained
+}
+
+// retained is an unmatched event held for coroutines that start waiting for
+// it late. Ticks is the number of ticks it has been offered again.
+type retained struct {
+	evt   Event
+	ticks int
+	at    time.Time
+}
+
+// SetUnmatchedHandler sets the function called when an event is dropped
+// because no coroutine was waiting for it. Set to nil to remove the
+// handler.
+func (g *Group) SetUnmatchedHandler(fn func(evt Event)) {
+	g.unmatch.handler = fn
+}
+
+// UnmatchedCount returns the number of events that have been dropped
+// because no coroutine was waiting for them.
+func (g *Group) UnmatchedCount() int {
+	return g.unmatch.count
+}
+
+// RetainUnmatched holds events that no coroutine was waiting for so that
+// coroutines which start waiting for them on a later tick still receive
+// them. At the start of each tick, retained events are delivered again
+// before any newly posted events. An event is kept until a coroutine
+// accepts it, until it has been offered on the given number of ticks, or
+// until the duration has elapsed. Use zero for no limit on ticks or on
+// time. Retention is disabled when both are zero, which is the default.
+func (g *Group) RetainUnmatched(ticks int, d time.Duration) {
+	g.unmatch.ticks = ticks
+	g.unmatch.d = d
+	if ticks == 0 && d == 0 {
+		for _, r := range g.unmatch.retained {
+			g.drop(r.evt)
+		}
+		g.unmatch.retained = nil
+	}
+}
+
+// unmatched retains the event if enabled or otherwise drops it.
+func (g *Group) unmatched(evt Event) {
+	if g.unmatch.ticks == 0 && g.unmatch.d == 0 {
+		g.drop(evt)
+		return
+	}
+	g.unmatch.retained = append(g.unmatch.retained, retained{evt: evt, at: g.Now()})
+}
+
+func (g *Group) drop(evt Event) {
+	g.unmatch.count++
+	if g.unmatch.handler != nil {
+		g.unmatch.handler(evt)
+	}
+}
+
+// redeliver offers the retained events to the coroutines waiting now.
+// Events that are still not accepted are dropped once they expire.
+func (g *Group) redeliver(now time.Time) {
+	if len(g.unmatch.retained) == 0 {
+		return
+	}
+	pending := g.unmatch.retained
+	g.unmatch.retained = nil
+	var kept []retained
+	for _, r := range pending {
+		g.setDepth(1)
+		accepted := g.deliver(r.evt)
+		g.settle()
+		g.setDepth(0)
+		if accepted {
+			continue
+		}
+		r.ticks++
+		if (g.unmatch.ticks > 0 && r.ticks >= g.unmatch.ticks) ||
+			(g.unmatch.d > 0 && now.Sub(r.at) >= g.unmatch.d) {
+			g.drop(r.evt)
+			continue
+		}
+		kept = append(kept, r)
+	}
+	g.unmatch.retained = kept
+}
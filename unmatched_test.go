@@ -0,0 +1,121 @@
+package coroutine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmatchedHandler(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	var dropped []Event
+	g.SetUnmatchedHandler(func(evt Event) {
+		dropped = append(dropped, evt)
+	})
+	g.NewCoroutine(func(co *C) {
+		co.WaitFor(testEvent("ramp"))
+		co.WaitFor(testEvent("end"))
+	})
+	g.Post(testEvent("ramp"))
+	g.Post(testEvent("rmap"))
+	g.Post(testEvent("ramp"))
+	g.Tick()
+
+	want := []Event{testEvent("rmap"), testEvent("ramp")}
+	if !reflect.DeepEqual(dropped, want) {
+		t.Errorf("\n have: %v \n want: %v", dropped, want)
+	}
+	if g.UnmatchedCount() != 2 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 2)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestRetainUnmatchedTicks(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, _ := newMockGroup()
+	g.RetainUnmatched(2, 0)
+	var dropped []Event
+	g.SetUnmatchedHandler(func(evt Event) {
+		dropped = append(dropped, evt)
+	})
+	received := 0
+	g.Post(testEvent("ramp"))
+	g.Post(testEvent("orbit"))
+	g.Tick()
+	if len(dropped) != 0 {
+		t.Errorf("\n have: %v \n want: %v", dropped, nil)
+	}
+
+	// Starts one tick too late
+	g.NewCoroutine(func(co *C) {
+		for {
+			if _, done := co.WaitFor(testEvent("ramp")); done {
+				return
+			}
+			received++
+		}
+	})
+	g.Tick()
+	if received != 1 {
+		t.Errorf("\n have: %v \n want: %v", received, 1)
+	}
+	g.Tick()
+	want := []Event{testEvent("orbit")}
+	if !reflect.DeepEqual(dropped, want) {
+		t.Errorf("\n have: %v \n want: %v", dropped, want)
+	}
+	if received != 1 {
+		t.Errorf("\n have: %v \n want: %v", received, 1)
+	}
+	g.Stop()
+	wd.Stop()
+}
+
+func TestRetainUnmatchedDuration(t *testing.T) {
+	wd := NewWatchdog(1 * time.Second)
+	g, clk := newMockGroup()
+	g.RetainUnmatched(0, 100*time.Millisecond)
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	for i := 0; i < 5; i++ {
+		clk.Add(16 * time.Millisecond)
+		g.Tick()
+	}
+	if g.UnmatchedCount() != 0 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 0)
+	}
+
+	received := false
+	g.NewCoroutine(func(co *C) {
+		co.WaitFor(testEvent("ramp"))
+		received = true
+	})
+	clk.Add(16 * time.Millisecond)
+	g.Tick()
+	if !received {
+		t.Errorf("\n have: %v \n want: %v", received, true)
+	}
+
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	clk.Add(100 * time.Millisecond)
+	g.Tick()
+	if g.UnmatchedCount() != 1 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 1)
+	}
+	wd.Stop()
+}
+
+func TestRetainUnmatchedDisable(t *testing.T) {
+	g, _ := newMockGroup()
+	g.RetainUnmatched(10, 0)
+	g.Post(testEvent("ramp"))
+	g.Tick()
+	g.RetainUnmatched(0, 0)
+	if g.UnmatchedCount() != 1 {
+		t.Errorf("\n have: %v \n want: %v", g.UnmatchedCount(), 1)
+	}
+}